@@ -0,0 +1,299 @@
+package egowebapi
+
+import (
+	"errors"
+	"fmt"
+	"github.com/egovorukhin/egowebapi/consts"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// typedHandler обработчик вида func(c *Context, in *In) (*Out, error)
+type typedHandler struct {
+	fn     reflect.Value
+	in     reflect.Type
+	out    reflect.Type
+	hasOut bool
+}
+
+var (
+	contextType = reflect.TypeOf((*Context)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// newTypedHandler проверка сигнатуры типизированного обработчика
+func newTypedHandler(fn interface{}) (*typedHandler, error) {
+
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, errors.New("typed handler must be a function")
+	}
+	t := v.Type()
+
+	h := &typedHandler{fn: v}
+
+	if t.NumIn() < 1 || t.NumIn() > 2 || t.In(0) != contextType {
+		return nil, fmt.Errorf("typed handler %s: want func(*Context[, *In]) ([Out, ]error)", t)
+	}
+	if t.NumIn() == 2 {
+		h.in = t.In(1)
+		if h.in.Kind() != reflect.Ptr || h.in.Elem().Kind() != reflect.Struct {
+			return nil, fmt.Errorf("typed handler %s: input must be a pointer to struct", t)
+		}
+	}
+
+	switch t.NumOut() {
+	case 1:
+	case 2:
+		h.out = t.Out(0)
+		h.hasOut = true
+	default:
+		return nil, fmt.Errorf("typed handler %s: want func(*Context[, *In]) ([Out, ]error)", t)
+	}
+	if t.Out(t.NumOut()-1) != errorType {
+		return nil, fmt.Errorf("typed handler %s: last result must be error", t)
+	}
+
+	return h, nil
+}
+
+// handler обработчик маршрута: заполнение входной модели, вызов функции и отправка результата
+func (h *typedHandler) handler(c *Context) error {
+
+	args := []reflect.Value{reflect.ValueOf(c)}
+	if h.in != nil {
+		in := reflect.New(h.in.Elem())
+		if err := bind(c, in.Elem()); err != nil {
+			return NewError(consts.StatusBadRequest, err)
+		}
+		args = append(args, in)
+	}
+
+	results := h.fn.Call(args)
+
+	if err, _ := results[len(results)-1].Interface().(error); err != nil {
+		return err
+	}
+	if !h.hasOut {
+		return nil
+	}
+
+	out := results[0]
+	if (out.Kind() == reflect.Ptr || out.Kind() == reflect.Interface) && out.IsNil() {
+		return c.SendStatus(204)
+	}
+	return c.JSON(200, out.Interface())
+}
+
+// Bind Заполнение структуры по тэгам ewa из параметров пути, адресной строки,
+// заголовков и тела запроса, как для входной модели SetTypedHandler
+func (c *Context) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Bind: %T is not a pointer to struct", v)
+	}
+	if err := bind(c, rv.Elem()); err != nil {
+		return NewError(consts.StatusBadRequest, err)
+	}
+	return nil
+}
+
+// bindTag параметр модели, описанный тэгом ewa
+type bindTag struct {
+	in       string
+	name     string
+	required bool
+}
+
+// parseBindTags разбор тэга ewa по тем же правилам, что и в ModelToParameters
+func parseBindTags(field reflect.StructField) (tags []bindTag) {
+
+	tag, ok := field.Tag.Lookup(tagEWA)
+	if !ok {
+		return
+	}
+
+	for _, tagValue := range strings.Split(tag, ";") {
+		inNames := strings.Split(tagValue, ":")
+		if len(inNames) < 2 || inNames[1] == "" {
+			continue
+		}
+		t := bindTag{
+			in:   strings.ToLower(strings.Trim(inNames[0], " ")),
+			name: strings.ToLower(field.Name),
+		}
+		switch t.in {
+		case InPath, InHeader, InQuery, InBody:
+		default:
+			continue
+		}
+		for _, value := range strings.Split(inNames[1], ",") {
+			items := strings.Split(value, "=")
+			item := strings.ToLower(strings.Trim(items[0], " "))
+			switch {
+			case item == "required":
+				t.required = true
+			case item == "name" && len(items) > 1:
+				t.name = items[1]
+			}
+		}
+		tags = append(tags, t)
+	}
+
+	return
+}
+
+// bind заполнение модели из параметров пути, адресной строки, заголовков и тела запроса
+func bind(c *Context, v reflect.Value) error {
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := bind(c, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !fv.CanSet() {
+			continue
+		}
+
+		for _, tag := range parseBindTags(field) {
+			var values []string
+			switch tag.in {
+			case InBody:
+				if len(c.Body()) == 0 {
+					if tag.required {
+						return errors.New("request body is required")
+					}
+					continue
+				}
+				if err := c.BodyParser(fv.Addr().Interface()); err != nil {
+					return fmt.Errorf("body: %s", err)
+				}
+				continue
+			case InPath:
+				if value := c.Params(tag.name); value != "" {
+					values = []string{value}
+				}
+			case InQuery:
+				values = c.QueryValues()[tag.name]
+			case InHeader:
+				if value := c.Get(tag.name); value != "" {
+					values = []string{value}
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			if err := setValue(fv, values); err != nil {
+				return fmt.Errorf("%s parameter %s: %s", tag.in, tag.name, err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// setValue преобразование строковых значений в тип поля
+func setValue(v reflect.Value, values []string) error {
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(s.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	value := values[0]
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// modelName имя модели для swagger: ищем среди зарегистрированных, иначе добавляем по имени типа.
+// Если имя типа уже занято другой моделью, то возвращается ошибка
+func (m Models) modelName(t reflect.Type) (name string, isArray bool, err error) {
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		isArray = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return "", isArray, nil
+	}
+
+	if key, ok := m.key(t); ok {
+		return key, isArray, nil
+	}
+
+	name = t.Name()
+	if name == "" {
+		return "", isArray, nil
+	}
+	if model, ok := m[name]; ok {
+		return "", isArray, fmt.Errorf("model %s is already registered for %T, register %s under another name with SetModels", name, model, t)
+	}
+	m[name] = reflect.New(t).Elem().Interface()
+	return name, isArray, nil
+}
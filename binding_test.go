@@ -0,0 +1,176 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type Pet struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type PetIn struct {
+	Id    int      `ewa:"path:/{id}"`
+	Tags  []string `ewa:"query:name=tag"`
+	Token string   `ewa:"header:name=X-Token"`
+	Pet   Pet      `ewa:"body:required"`
+}
+
+type Pets struct{}
+
+func (Pets) Put(route *Route) {
+	route.SetTypedHandler(func(c *Context, in *PetIn) (*Pet, error) {
+		if in.Token != "secret" {
+			return nil, errors.New(strings.Join(in.Tags, ","))
+		}
+		in.Pet.Id = in.Id
+		return &in.Pet, nil
+	})
+}
+
+func (Pets) Delete(route *Route) {
+	route.SetTypedHandler(func(c *Context) (*Pet, error) {
+		return nil, nil
+	})
+}
+
+func TestRoute_SetTypedHandler(t *testing.T) {
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				if err := handler(NewContext(c)); err != nil {
+					return c.SendString(500, err.Error())
+				}
+				return nil
+			})
+		},
+	})
+	s.Register(new(Pets)).SetPath("/pets")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("PUT", "/pets/7?tag=a&tag=b", strings.NewReader(`{"name":"Rex"}`))
+	r.Header.Set("X-Token", "secret")
+	w := httptest.NewRecorder()
+	web.ServeHTTP(w, r)
+	var pet Pet
+	if err := json.Unmarshal(w.Body.Bytes(), &pet); err != nil || w.Code != 200 || pet.Id != 7 || pet.Name != "Rex" {
+		t.Errorf("status %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("PUT", "/pets/7?tag=a&tag=b", strings.NewReader(`{}`)))
+	if w.Code != 500 || !strings.Contains(w.Body.String(), `"detail":"a,b"`) {
+		t.Errorf("status %d, body %s, want 500 a,b", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("PUT", "/pets/x", strings.NewReader(`{}`)))
+	if w.Code != 400 {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("PUT", "/pets/7", nil))
+	if w.Code != 400 {
+		t.Errorf("status %d, want 400: body is required", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("DELETE", "/pets", nil))
+	if w.Code != 204 {
+		t.Errorf("status %d, want 204", w.Code)
+	}
+
+	// Swagger
+	put, ok := s.Swagger.Paths["pets/{id}"]["put"]
+	if !ok {
+		t.Fatalf("paths %v", s.Swagger.Paths)
+	}
+	var in []string
+	for _, param := range put.Parameters {
+		in = append(in, param.In+":"+param.Name)
+	}
+	if got := strings.Join(in, ","); got != "path:id,query:tag,header:X-Token,body:body" {
+		t.Errorf("parameters %s", got)
+	}
+	if _, ok := s.Swagger.Definitions["Pet"]; !ok {
+		t.Errorf("definition Pet is not registered")
+	}
+	if _, ok := put.Responses["200"]; !ok {
+		t.Errorf("responses %v", put.Responses)
+	}
+	if _, ok := s.Swagger.Paths["pets"]["delete"].Responses["204"]; !ok {
+		t.Errorf("delete responses %v", s.Swagger.Paths["pets"]["delete"].Responses)
+	}
+
+	// Ошибка сигнатуры
+	route := &Route{}
+	route.SetTypedHandler(func(in *PetIn) error { return nil })
+	if route.err == nil {
+		t.Error("want signature error")
+	}
+
+	// Другой тип с именем уже зарегистрированной модели
+	type Pet struct {
+		Kind string
+	}
+	route = &Route{Operation: Operation{Responses: map[string]*Response{}}, models: Models{"Pet": Pet{}}}
+	route.SetTypedHandler(func(c *Context) (*Pet, error) { return nil, nil })
+	if route.err != nil {
+		t.Errorf("registered model: %v", route.err)
+	}
+	route.models = Models{"Pet": PetIn{}}
+	route.SetTypedHandler(func(c *Context) (*Pet, error) { return nil, nil })
+	if route.err == nil {
+		t.Error("want model name conflict")
+	}
+}
+
+func TestContext_Bind(t *testing.T) {
+
+	var (
+		in  PetIn
+		err error
+	)
+	web := &nethttp.Server{}
+	web.Add("PUT", "/pets/{id}", nethttp.HandlerFunc(func(c *nethttp.Context) error {
+		ctx := NewContext(c)
+		in = PetIn{}
+		if err = ctx.Bind(&in); err != nil {
+			return nil
+		}
+		// Не указатель на структуру - ошибка программы, а не запроса
+		var e *Error
+		if bindErr := ctx.Bind(in); bindErr == nil || errors.As(bindErr, &e) {
+			t.Errorf("bind to value: %v", bindErr)
+		}
+		return nil
+	}))
+
+	r := httptest.NewRequest("PUT", "/pets/7?tag=a&tag=b", strings.NewReader(`{"name":"Rex"}`))
+	r.Header.Set("X-Token", "secret")
+	web.ServeHTTP(httptest.NewRecorder(), r)
+	if err != nil || in.Id != 7 || strings.Join(in.Tags, ",") != "a,b" || in.Token != "secret" || in.Pet.Name != "Rex" {
+		t.Errorf("bind %+v: %v", in, err)
+	}
+
+	web.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/pets/x", strings.NewReader(`{}`)))
+	var e *Error
+	if !errors.As(err, &e) || e.Status != 400 {
+		t.Errorf("invalid path parameter: %v, want 400", err)
+	}
+
+	web.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/pets/7", nil))
+	if !errors.As(err, &e) || e.Status != 400 {
+		t.Errorf("missing body: %v, want 400", err)
+	}
+}
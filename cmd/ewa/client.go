@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/egovorukhin/egowebapi/codegen"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// genClient формирование Go клиента по документу swagger 2.0, созданному командой spec
+func genClient(args []string) error {
+
+	fs := flag.NewFlagSet("gen-client", flag.ExitOnError)
+	in := fs.String("spec", "swagger.json", "документ swagger 2.0")
+	pkg := fs.String("pkg", "", "имя пакета клиента, по умолчанию имя каталога -out")
+	out := fs.String("out", "", "файл для записи клиента, по умолчанию стандартный вывод")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	spec, err := codegen.ParseSpec(b)
+	if err != nil {
+		return err
+	}
+
+	name := *pkg
+	if name == "" && *out != "" {
+		abs, err := filepath.Abs(*out)
+		if err != nil {
+			return err
+		}
+		name = filepath.Base(filepath.Dir(abs))
+	}
+	if name == "" {
+		return errors.New("укажите имя пакета -pkg")
+	}
+
+	b, err = codegen.GenerateClient(spec, codegen.ClientOptions{Package: name})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(*out, b, 0644)
+}
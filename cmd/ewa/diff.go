@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/egovorukhin/egowebapi/codegen"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// diff сравнение двух документов swagger 2.0. Отчет в формате JSON выводится
+// в стандартный вывод, при несовместимых изменениях команда завершается с кодом 1
+func diff(args []string) error {
+
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	text := fs.Bool("text", false, "вывести отчет в текстовом виде")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("укажите два документа: ewa diff old.json new.json")
+	}
+
+	specs := make([]*codegen.Spec, 2)
+	for i, name := range fs.Args() {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if specs[i], err = codegen.ParseSpec(b); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+	}
+
+	report := codegen.Diff(specs[0], specs[1])
+	if *text {
+		for _, c := range report.Changes {
+			level := "info"
+			if c.Breaking {
+				level = "BREAKING"
+			}
+			where := strings.Join(strings.Fields(strings.ToUpper(c.Method)+" "+c.Path+" "+c.Target), " ")
+			fmt.Printf("%-8s %s: %s (%s)\n", level, where, c.Message, c.Kind)
+		}
+	} else {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		if err := e.Encode(report); err != nil {
+			return err
+		}
+	}
+
+	if report.Breaking {
+		return errors.New("обнаружены несовместимые изменения")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Команда ewa - инструменты для проектов на EgoWebApi.
+//
+//	ewa spec -pkg github.com/user/app/api -func Register -out swagger.json
+//	ewa gen-client -spec swagger.json -out client/client.go
+//	ewa diff old.json new.json
+//	ewa scaffold -out . spec.json
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"spec", "сформировать документ swagger из функции регистрации контроллеров", spec},
+	{"gen-client", "сформировать Go клиент по документу swagger", genClient},
+	{"scaffold", "сформировать каркас контроллеров и моделей по документу swagger/OpenAPI", scaffold},
+	{"diff", "найти несовместимые изменения между двумя документами swagger", diff},
+}
+
+func main() {
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "ewa %s: %s\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: ewa <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.usage)
+	}
+}
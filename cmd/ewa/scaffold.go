@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/egovorukhin/egowebapi/codegen"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// scaffold формирование каркаса контроллеров и моделей по документу swagger/OpenAPI
+func scaffold(args []string) error {
+
+	fs := flag.NewFlagSet("scaffold", flag.ExitOnError)
+	out := fs.String("out", ".", "каталог для записи файлов")
+	module := fs.String("module", "", "путь импорта каталога -out, по умолчанию определяется по go.mod")
+	force := fs.Bool("force", false, "перезаписывать существующие файлы")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("укажите документ: ewa scaffold spec.json")
+	}
+
+	b, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	spec, err := codegen.ParseSpec(b)
+	if err != nil {
+		return err
+	}
+
+	if *module == "" {
+		if *module, err = modulePath(*out); err != nil {
+			return err
+		}
+	}
+
+	files, err := codegen.Scaffold(spec, codegen.ScaffoldOptions{Module: *module})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(*out, filepath.FromSlash(name))
+		if _, err = os.Stat(path); err == nil && !*force {
+			fmt.Fprintln(os.Stderr, "skip existing", path)
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(path, files[name], 0644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+
+	return nil
+}
+
+// modulePath путь импорта каталога по ближайшему go.mod
+func modulePath(dir string) (string, error) {
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		f, err := os.Open(filepath.Join(root, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			s := bufio.NewScanner(f)
+			for s.Scan() {
+				line := strings.TrimSpace(s.Text())
+				if strings.HasPrefix(line, "module ") {
+					rel, err := filepath.Rel(root, abs)
+					if err != nil {
+						return "", err
+					}
+					path := strings.Trim(strings.TrimPrefix(line, "module "), `" `)
+					if rel != "." {
+						path += "/" + filepath.ToSlash(rel)
+					}
+					return path, nil
+				}
+			}
+			return "", fmt.Errorf("%s: module directive not found", f.Name())
+		}
+		if filepath.Dir(root) == root {
+			return "", errors.New("go.mod not found, specify -module")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+)
+
+// specTemplate программа, которая вызывает функцию регистрации пользователя,
+// формирует маршруты без запуска слушателя и записывает документ swagger в файл.
+// Функция регистрации может иметь вид func(*ewa.Server) или func() *ewa.Server
+var specTemplate = template.Must(template.New("spec").Parse(`// Code generated by ewa spec. DO NOT EDIT.
+package main
+
+import (
+	"fmt"
+	ewa "github.com/egovorukhin/egowebapi"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"io/ioutil"
+	"os"
+	reg "{{.Pkg}}"
+)
+
+func main() {
+
+	var ws *ewa.Server
+	switch f := interface{}(reg.{{.Func}}).(type) {
+	case func(*ewa.Server):
+		ws = ewa.New(&nethttp.Server{}, ewa.Config{})
+		f(ws)
+	case func() *ewa.Server:
+		ws = f()
+	default:
+		fmt.Fprintln(os.Stderr, "{{.Func}} must be func(*ewa.Server) or func() *ewa.Server")
+		os.Exit(1)
+	}
+
+	// Сервер формируется на адаптере net/http
+	ws.WebServer = &nethttp.Server{}
+	ws.Config.ContextHandler = func(handler ewa.Handler) interface{} {
+		return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+			return handler(ewa.NewContext(c))
+		})
+	}
+
+	if err := ws.Build(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Замечания к документу выводятся как предупреждения
+	for _, issue := range ws.Swagger.Lint() {
+		fmt.Fprintln(os.Stderr, "warning:", issue)
+	}
+
+{{if .Version}}	ws.Swagger.SetOpenAPIVersion({{printf "%q" .Version}})
+{{end}}	b, err := ws.Swagger.JSON()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err = ioutil.WriteFile({{printf "%q" .Out}}, b, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+// spec формирование документа swagger
+func spec(args []string) error {
+
+	fs := flag.NewFlagSet("spec", flag.ExitOnError)
+	pkg := fs.String("pkg", "", "пакет с функцией регистрации контроллеров")
+	fn := fs.String("func", "Register", "имя функции регистрации контроллеров")
+	out := fs.String("out", "swagger.json", "файл для записи документа")
+	dir := fs.String("dir", ".", "каталог модуля, из которого импортируется пакет")
+	version := fs.String("version", "", "версия документа: 2.0, 3.0.3 или 3.1.0")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pkg == "" {
+		return errors.New("укажите пакет -pkg")
+	}
+
+	output, err := filepath.Abs(*out)
+	if err != nil {
+		return err
+	}
+
+	// Временный пакет должен находиться внутри модуля пользователя,
+	// чтобы импорт пакета с регистрацией разрешился
+	tmp, err := ioutil.TempDir(*dir, "ewa-spec-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	f, err := os.Create(filepath.Join(tmp, "main.go"))
+	if err != nil {
+		return err
+	}
+	err = specTemplate.Execute(f, map[string]string{
+		"Pkg":     *pkg,
+		"Func":    *fn,
+		"Out":     output,
+		"Version": *version,
+	})
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(tmp))
+	cmd.Dir = *dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,543 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ClientOptions параметры генерации клиента
+type ClientOptions struct {
+	// Package имя пакета клиента
+	Package string
+}
+
+// GenerateClient формирование пакета Go клиента по документу swagger 2.0:
+// структуры для определений моделей и по одному методу на каждую операцию
+func GenerateClient(spec *Spec, options ClientOptions) ([]byte, error) {
+
+	if options.Package == "" {
+		options.Package = "client"
+	}
+
+	g := &clientGenerator{
+		spec: spec,
+		data: clientData{
+			Package: options.Package,
+			BaseURL: baseURL(spec),
+		},
+		goTypes: goTypes{models: map[string]string{}},
+	}
+
+	// Имена типов пакета не должны совпадать между собой и с идентификаторами шаблона
+	typeNames := map[string]bool{}
+	for name := range clientIdentifiers {
+		typeNames[name] = true
+	}
+	for _, name := range sortedKeys(spec.Definitions) {
+		g.models[name] = uniqueName(typeNames, exportedName(name), "Model")
+	}
+	for _, name := range sortedKeys(spec.Definitions) {
+		g.data.Models = append(g.data.Models, g.model(name, spec.Definitions[name]))
+	}
+
+	// Имена методов не должны совпадать с полями и методами Client
+	methodNames := map[string]bool{}
+	for name := range clientMembers {
+		methodNames[name] = true
+	}
+	for _, path := range sortedKeys(spec.Paths) {
+		for _, method := range sortedKeys(spec.Paths[path]) {
+			op, err := g.operation(path, method, spec.Paths[path][method])
+			if err != nil {
+				return nil, err
+			}
+			op.Name = uniqueName(methodNames, op.Name, "")
+			op.ParamsType = uniqueName(typeNames, op.Name+"Params", "")
+			g.data.Operations = append(g.data.Operations, op)
+		}
+	}
+
+	for _, name := range sortedSecurity(spec.SecurityDefinitions) {
+		d := spec.SecurityDefinitions[name]
+		if d.Scheme != "" {
+			d.Type = d.Scheme
+		}
+		g.data.Schemes = append(g.data.Schemes, clientScheme{
+			Name: name,
+			Type: d.Type,
+			In:   d.In,
+			Key:  d.Name,
+		})
+	}
+
+	g.data.NeedsTime = g.needsTime
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, g.data); err != nil {
+		return nil, err
+	}
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %s\n%s", err, buf.Bytes())
+	}
+	if err = typeCheck(options.Package, b); err != nil {
+		return nil, fmt.Errorf("type check generated client: %s", err)
+	}
+	return b, nil
+}
+
+// clientIdentifiers идентификаторы пакета, которые объявляет шаблон клиента
+var clientIdentifiers = map[string]bool{
+	"DefaultBaseURL": true, "Client": true, "New": true, "Error": true,
+	"securityScheme": true, "securitySchemes": true, "addValue": true,
+}
+
+// clientMembers поля и методы Client
+var clientMembers = map[string]bool{
+	"BaseURL": true, "HTTPClient": true, "Username": true, "Password": true, "ApiKey": true, "do": true,
+}
+
+// uniqueName имя, которого еще нет в taken. При совпадении сначала добавляется
+// suffix, затем номер
+func uniqueName(taken map[string]bool, name, suffix string) string {
+	if taken[name] && suffix != "" {
+		name += suffix
+	}
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// typeCheck проверка типов сгенерированного пакета
+func typeCheck(pkg string, src []byte) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(pkg, fset, []*ast.File{f}, nil)
+	return err
+}
+
+type clientGenerator struct {
+	goTypes
+	spec *Spec
+	data clientData
+}
+
+type clientData struct {
+	Package    string
+	BaseURL    string
+	Models     []clientModel
+	Operations []clientOperation
+	Schemes    []clientScheme
+	NeedsTime  bool
+}
+
+type clientModel struct {
+	Name        string
+	Description string
+	Type        string
+	Fields      []clientField
+}
+
+type clientField struct {
+	Name        string
+	Type        string
+	Tag         string
+	Description string
+}
+
+type clientOperation struct {
+	Name        string
+	Method      string
+	Path        string
+	PathParams  []clientParam
+	Params      []clientParam
+	Body        string
+	Result      string
+	ResultPtr   bool
+	Security    []string
+	ParamsType  string
+	Description string
+}
+
+type clientParam struct {
+	Name     string
+	Key      string
+	In       string
+	Type     string
+	Required bool
+}
+
+type clientScheme struct {
+	Name string
+	Type string
+	In   string
+	Key  string
+}
+
+// model структура для определения
+func (g *clientGenerator) model(name string, s *Schema) clientModel {
+
+	m := clientModel{
+		Name:        g.models[name],
+		Description: s.Description,
+	}
+	if s.Type != "object" || s.Properties == nil {
+		m.Type = g.goType(s)
+		return m
+	}
+
+	fields := map[string]bool{}
+	for _, key := range s.Properties.Keys {
+		tag := key
+		if !contains(s.Required, key) {
+			tag += ",omitempty"
+		}
+		property := s.Properties.Values[key]
+		t := g.goType(property)
+		// Необязательная вложенная модель
+		if property.Ref != "" && !contains(s.Required, key) {
+			t = "*" + t
+		}
+		m.Fields = append(m.Fields, clientField{
+			Name:        uniqueName(fields, exportedName(key), ""),
+			Type:        t,
+			Tag:         fmt.Sprintf("`json:%q`", tag),
+			Description: property.Description,
+		})
+	}
+
+	return m
+}
+
+// operation метод клиента для операции
+func (g *clientGenerator) operation(path, method string, o *Operation) (op clientOperation, err error) {
+
+	name := o.ID
+	if name == "" {
+		name = method + "-" + path
+	}
+	op = clientOperation{
+		Name:        exportedName(name),
+		Method:      strings.ToUpper(method),
+		Path:        "/" + strings.TrimPrefix(path, "/"),
+		Description: strings.TrimSpace(o.Summary + " " + o.Description),
+	}
+	locals, fields := map[string]bool{}, map[string]bool{}
+	for _, p := range o.Parameters {
+		switch p.In {
+		case "path":
+			op.PathParams = append(op.PathParams, clientParam{
+				Name: uniqueName(locals, localName(p.Name), ""),
+				Key:  p.Name,
+				In:   p.In,
+				Type: g.paramType(p),
+			})
+		case "query", "header":
+			op.Params = append(op.Params, clientParam{
+				Name:     uniqueName(fields, exportedName(p.Name), ""),
+				Key:      p.Name,
+				In:       p.In,
+				Type:     g.paramType(p),
+				Required: p.Required,
+			})
+		case "body":
+			if p.Schema != nil {
+				op.Body = g.goType(p.Schema)
+			}
+		case "formData":
+			return op, fmt.Errorf("operation %s %s: formData parameters are not supported", op.Method, path)
+		}
+	}
+
+	// Ответ: первый успешный код со схемой
+	for _, code := range sortedKeys(o.Responses) {
+		r := o.Responses[code]
+		if len(code) != 3 || code[0] != '2' || r.Schema == nil {
+			continue
+		}
+		op.Result = g.goType(r.Schema)
+		op.ResultPtr = r.Schema.Ref != ""
+		break
+	}
+
+	for _, sec := range o.Security {
+		for key := range sec {
+			op.Security = append(op.Security, key)
+		}
+	}
+	sort.Strings(op.Security)
+
+	return op, nil
+}
+
+// baseURL адрес сервера по умолчанию
+func baseURL(spec *Spec) string {
+	basePath := strings.TrimRight(spec.BasePath, "/")
+	if spec.Host == "" {
+		return basePath
+	}
+	scheme := "http"
+	if len(spec.Schemes) > 0 {
+		scheme = spec.Schemes[0]
+	}
+	return scheme + "://" + spec.Host + basePath
+}
+
+func sortedSecurity(m map[string]SecurityDefinition) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var clientTemplate = template.Must(template.New("client").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"lines": func(s string) []string { return strings.Split(strings.TrimSpace(s), "\n") },
+}).Parse(`// Code generated by ewa gen-client. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+{{- if .NeedsTime}}
+	"time"
+{{- end}}
+)
+
+// DefaultBaseURL адрес сервера из документа
+const DefaultBaseURL = {{quote .BaseURL}}
+{{range .Models}}
+{{- if .Description}}
+{{range lines .Description}}// {{.}}
+{{end}}
+{{- else}}
+// {{.Name}} модель {{.Name}}
+{{- end}}
+{{- if .Type}}
+type {{.Name}} {{.Type}}
+{{else}}
+type {{.Name}} struct {
+{{- range .Fields}}
+{{- if .Description}}
+	// {{.Description}}
+{{- end}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+{{end}}
+{{- end}}
+// Client клиент сервиса
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Username, Password учетные данные для схемы basic
+	Username string
+	Password string
+	// ApiKey ключ для схемы apiKey
+	ApiKey string
+}
+
+// New Инициализация клиента, если адрес пустой, то используется DefaultBaseURL
+func New(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Error ответ сервера с кодом не 2xx
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+type securityScheme struct {
+	Type string
+	In   string
+	Name string
+}
+
+var securitySchemes = map[string]securityScheme{
+{{- range .Schemes}}
+	{{quote .Name}}: {Type: {{quote .Type}}, In: {{quote .In}}, Name: {{quote .Key}}},
+{{- end}}
+}
+{{range .Operations}}
+{{- if .Params}}
+// {{.ParamsType}} параметры адресной строки и заголовков {{.Name}}
+type {{.ParamsType}} struct {
+{{- range .Params}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+// {{.Name}} {{.Method}} {{.Path}}
+{{- if .Description}}
+//
+{{- range lines .Description}}
+// {{.}}
+{{- end}}
+{{- end}}
+func (c *Client) {{.Name}}(ctx context.Context
+{{- range .PathParams}}, {{.Name}} {{.Type}}{{end}}
+{{- if .Params}}, params *{{.ParamsType}}{{end}}
+{{- if .Body}}, body {{.Body}}{{end}}) {{if .Result}}({{if .ResultPtr}}*{{end}}{{.Result}}, error){{else}}error{{end}} {
+	path := {{quote .Path}}
+{{- range .PathParams}}
+	path = strings.Replace(path, {{quote (printf "{%s}" .Key)}}, url.PathEscape(fmt.Sprint({{.Name}})), 1)
+{{- end}}
+	query, header := url.Values{}, http.Header{}
+{{- if .Params}}
+	if params == nil {
+		params = &{{.ParamsType}}{}
+	}
+{{- range .Params}}
+	addValue({{if eq .In "query"}}query{{else}}header{{end}}, {{quote .Key}}, params.{{.Name}}, {{.Required}})
+{{- end}}
+{{- end}}
+{{- if .Result}}
+	var out {{.Result}}
+	err := c.do(ctx, {{quote .Method}}, path, query, header, {{if .Body}}body{{else}}nil{{end}}, &out, {{template "security" .Security}})
+	return {{if .ResultPtr}}&out{{else}}out{{end}}, err
+{{- else}}
+	return c.do(ctx, {{quote .Method}}, path, query, header, {{if .Body}}body{{else}}nil{{end}}, nil, {{template "security" .Security}})
+{{- end}}
+}
+{{end}}
+// do выполнение запроса и разбор ответа
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}, security []string) error {
+
+	u := c.BaseURL + path
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	// Авторизация
+	basic := false
+	for _, name := range security {
+		scheme := securitySchemes[name]
+		switch scheme.Type {
+		case "basic":
+			basic = c.Username != ""
+		case "apiKey":
+			if c.ApiKey == "" {
+				break
+			}
+			if scheme.In == "query" {
+				query.Set(scheme.Name, c.ApiKey)
+			} else {
+				header.Set(scheme.Name, c.ApiKey)
+			}
+		}
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if basic {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &Error{StatusCode: resp.StatusCode, Body: b}
+	}
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+// addValue добавление значения параметра, нулевые значения необязательных параметров пропускаются
+func addValue(values map[string][]string, key string, value interface{}, required bool) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.IsZero() && !required) {
+		return
+	}
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			values[key] = append(values[key], fmt.Sprint(v.Index(i).Interface()))
+		}
+		return
+	}
+	if t, ok := value.(interface{ Format(string) string }); ok {
+		values[key] = append(values[key], t.Format("2006-01-02T15:04:05Z07:00"))
+		return
+	}
+	values[key] = append(values[key], fmt.Sprint(value))
+}
+{{define "security"}}{{if .}}[]string{ {{- range $i, $s := .}}{{if $i}}, {{end}}{{quote $s}}{{end -}} }{{else}}nil{{end}}{{end}}`))
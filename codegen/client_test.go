@@ -0,0 +1,179 @@
+package codegen
+
+import (
+	"bytes"
+	"flag"
+	ewa "github.com/egovorukhin/egowebapi"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"github.com/egovorukhin/egowebapi/security"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "обновить эталонные файлы")
+
+type Pet struct {
+	Id      int       `json:"id"`
+	Name    string    `json:"name"`
+	Tags    []string  `json:"tags,omitempty"`
+	Born    time.Time `json:"born,omitempty"`
+	Owner   *Owner    `json:"owner,omitempty"`
+	Details map[string]int
+}
+
+type Owner struct {
+	Name string `json:"name"`
+}
+
+type PetIn struct {
+	Id    int    `ewa:"path:/{id}"`
+	Limit int    `ewa:"query:name=limit"`
+	Trace string `ewa:"header:name=X-Trace"`
+}
+
+type NewPetIn struct {
+	Pet Pet `ewa:"body:required"`
+}
+
+type Pets struct{}
+
+func (Pets) Get(route *ewa.Route) {
+	route.SetSecurity(security.BasicAuth).SetSummary("Get pet").
+		SetTypedHandler(func(c *ewa.Context, in *PetIn) (*Pet, error) {
+			return nil, nil
+		})
+}
+
+func (Pets) Post(route *ewa.Route) {
+	route.SetSecurity(security.ApiKeyAuth).SetSummary("Create pet").
+		SetTypedHandler(func(c *ewa.Context, in *NewPetIn) ([]Pet, error) {
+			return nil, nil
+		})
+}
+
+func (Pets) Delete(route *ewa.Route) {
+	route.SetParameters(ewa.NewPathParam("/{id}")).SetHandler(func(c *ewa.Context) error {
+		return nil
+	})
+}
+
+// spec документ, который строит сервер
+func spec(t *testing.T) *Spec {
+	return buildSpec(t, func(ws *ewa.Server) {
+		ws.Register(new(Pets)).SetPath("/api/pets")
+	})
+}
+
+// buildSpec документ сервера с контроллерами, которые регистрирует функция
+func buildSpec(t *testing.T, register func(ws *ewa.Server)) *Spec {
+
+	ws := ewa.New(&nethttp.Server{}, ewa.Config{
+		Port: 8080,
+		Authorization: security.Authorization{
+			Basic:  &security.Basic{},
+			ApiKey: &security.ApiKey{KeyName: "X-Api-Key", Param: security.ParamHeader},
+		},
+		ContextHandler: func(handler ewa.Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(ewa.NewContext(c))
+			})
+		},
+	})
+	ws.Swagger.SetBasePath("/api")
+	register(ws)
+	if err := ws.Build(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ws.Swagger.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSpec(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGenerateClient(t *testing.T) {
+
+	b, err := GenerateClient(spec(t), ClientOptions{Package: "petstore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "client.golden")
+	if *update {
+		if err = ioutil.WriteFile(golden, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("generated client differs from %s, run go test -update\n%s", golden, b)
+	}
+}
+
+func TestGenerateClient_Names(t *testing.T) {
+
+	// Модели совпадают с идентификаторами шаблона, между собой и с типом параметров
+	s, err := ParseSpec([]byte(`{
+		"swagger": "2.0",
+		"paths": {
+			"/list": {"get": {"operationId": "list", "parameters": [
+				{"name": "page", "in": "query", "type": "integer", "required": true},
+				{"name": "Page", "in": "query", "type": "integer"}
+			], "responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Error"}}}}},
+			"/base": {"get": {"operationId": "BaseURL", "responses": {"204": {"description": "OK"}}}}
+		},
+		"definitions": {
+			"Error": {"type": "object", "properties": {"code": {"type": "integer"}}},
+			"Client": {"type": "object", "properties": {"user_id": {"type": "string"}, "userId": {"type": "string"}}},
+			"user": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"User": {"type": "object", "properties": {"error": {"$ref": "#/definitions/Error"}}},
+			"ListParams": {"type": "string"}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := GenerateClient(s, ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"type ErrorModel struct", "type ClientModel struct", "type User struct", "type UserModel struct",
+		"type ListParams string", "type ListParams2 struct", "UserId2 string", "Page2 int",
+		"Error *ErrorModel", "func (c *Client) BaseURL2(", "(*ErrorModel, error)",
+		// Обязательный параметр отправляется и с нулевым значением
+		`addValue(query, "page", params.Page, true)`, `addValue(query, "Page", params.Page2, false)`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("generated client without %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	if _, err := ParseSpec([]byte(`{"openapi":"4.0.0"}`)); err == nil {
+		t.Error("want unsupported version error")
+	}
+
+	// Документ OpenAPI 3 приводится к тому же виду, что и документ swagger 2.0
+	v3 := buildSpec(t, func(ws *ewa.Server) {
+		ws.Register(new(Pets)).SetPath("/api/pets")
+		ws.Swagger.SetOpenAPIVersion(ewa.OpenAPI30)
+	})
+	if report := Diff(spec(t), v3); len(report.Changes) > 0 {
+		t.Errorf("openapi changes %+v", report.Changes)
+	}
+	if v3.BasePath != "/api" || v3.Host != "localhost:8080" {
+		t.Errorf("server %s %s", v3.Host, v3.BasePath)
+	}
+}
@@ -0,0 +1,251 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Виды изменений
+const (
+	PathAdded            = "path-added"
+	PathRemoved          = "path-removed"
+	OperationAdded       = "operation-added"
+	OperationRemoved     = "operation-removed"
+	ParameterAdded       = "parameter-added"
+	ParameterRemoved     = "parameter-removed"
+	ParameterRequired    = "parameter-required"
+	ParameterOptional    = "parameter-optional"
+	ParameterTypeChanged = "parameter-type-changed"
+	ResponseAdded        = "response-added"
+	ResponseRemoved      = "response-removed"
+	ResponseTypeChanged  = "response-type-changed"
+	ModelAdded           = "model-added"
+	ModelRemoved         = "model-removed"
+	PropertyAdded        = "property-added"
+	PropertyRemoved      = "property-removed"
+	PropertyRequired     = "property-required"
+	PropertyOptional     = "property-optional"
+	PropertyTypeChanged  = "property-type-changed"
+)
+
+// Change изменение между двумя версиями документа
+type Change struct {
+	Kind     string `json:"kind"`
+	Breaking bool   `json:"breaking"`
+	Path     string `json:"path,omitempty"`
+	Method   string `json:"method,omitempty"`
+	// Target параметр (in:name), код ответа или модель (Model.property)
+	Target  string `json:"target,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report результат сравнения документов
+type Report struct {
+	Breaking bool     `json:"breaking"`
+	Changes  []Change `json:"changes"`
+}
+
+// Diff сравнение двух документов swagger 2.0. Несовместимыми считаются
+// изменения, после которых существующие клиенты перестают работать
+func Diff(old, new *Spec) *Report {
+	d := &differ{report: &Report{Changes: []Change{}}}
+	d.paths(old.Paths, new.Paths)
+	d.definitions(old.Definitions, new.Definitions)
+	return d.report
+}
+
+type differ struct {
+	report *Report
+}
+
+func (d *differ) add(c Change) {
+	if c.Breaking {
+		d.report.Breaking = true
+	}
+	d.report.Changes = append(d.report.Changes, c)
+}
+
+func (d *differ) paths(old, new map[string]map[string]*Operation) {
+	for _, path := range sortedKeys(old) {
+		if _, ok := new[path]; !ok {
+			d.add(Change{Kind: PathRemoved, Breaking: true, Path: path, Message: "path removed"})
+			continue
+		}
+		for _, method := range sortedKeys(old[path]) {
+			n, ok := new[path][method]
+			if !ok {
+				d.add(Change{Kind: OperationRemoved, Breaking: true, Path: path, Method: method, Message: "operation removed"})
+				continue
+			}
+			d.operation(path, method, old[path][method], n)
+		}
+		for _, method := range sortedKeys(new[path]) {
+			if _, ok := old[path][method]; !ok {
+				d.add(Change{Kind: OperationAdded, Path: path, Method: method, Message: "operation added"})
+			}
+		}
+	}
+	for _, path := range sortedKeys(new) {
+		if _, ok := old[path]; !ok {
+			d.add(Change{Kind: PathAdded, Path: path, Message: "path added"})
+		}
+	}
+}
+
+func (d *differ) operation(path, method string, old, new *Operation) {
+
+	change := func(kind string, breaking bool, target, format string, a ...interface{}) {
+		d.add(Change{
+			Kind:     kind,
+			Breaking: breaking,
+			Path:     path,
+			Method:   method,
+			Target:   target,
+			Message:  fmt.Sprintf(format, a...),
+		})
+	}
+
+	// Параметры сравниваются по месту и имени
+	params := map[string]*Parameter{}
+	for _, p := range old.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	seen := map[string]bool{}
+	for _, n := range new.Parameters {
+		key := n.In + ":" + n.Name
+		seen[key] = true
+		o, ok := params[key]
+		switch {
+		case !ok && n.Required:
+			change(ParameterAdded, true, key, "required parameter added")
+		case !ok:
+			change(ParameterAdded, false, key, "optional parameter added")
+		default:
+			if ot, nt := paramType(o), paramType(n); ot != nt {
+				change(ParameterTypeChanged, true, key, "type changed from %s to %s", ot, nt)
+			}
+			if !o.Required && n.Required {
+				change(ParameterRequired, true, key, "parameter became required")
+			}
+			if o.Required && !n.Required {
+				change(ParameterOptional, false, key, "parameter became optional")
+			}
+		}
+	}
+	for _, o := range old.Parameters {
+		if key := o.In + ":" + o.Name; !seen[key] {
+			change(ParameterRemoved, false, key, "parameter removed")
+		}
+	}
+
+	for _, code := range sortedKeys(old.Responses) {
+		n, ok := new.Responses[code]
+		if !ok {
+			change(ResponseRemoved, true, code, "response removed")
+			continue
+		}
+		if ot, nt := schemaType(old.Responses[code].Schema), schemaType(n.Schema); ot != nt {
+			change(ResponseTypeChanged, true, code, "type changed from %s to %s", ot, nt)
+		}
+	}
+	for _, code := range sortedKeys(new.Responses) {
+		if _, ok := old.Responses[code]; !ok {
+			change(ResponseAdded, false, code, "response added")
+		}
+	}
+}
+
+func (d *differ) definitions(old, new map[string]*Schema) {
+	for _, name := range sortedKeys(old) {
+		n, ok := new[name]
+		if !ok {
+			d.add(Change{Kind: ModelRemoved, Breaking: true, Target: name, Message: "model removed"})
+			continue
+		}
+		d.properties(name, old[name], n)
+	}
+	for _, name := range sortedKeys(new) {
+		if _, ok := old[name]; !ok {
+			d.add(Change{Kind: ModelAdded, Target: name, Message: "model added"})
+		}
+	}
+}
+
+func (d *differ) properties(model string, old, new *Schema) {
+
+	oldProps, newProps := properties(old), properties(new)
+	oldRequired, newRequired := required(old), required(new)
+
+	for _, name := range oldProps.Keys {
+		target := model + "." + name
+		n, ok := newProps.Values[name]
+		if !ok {
+			d.add(Change{Kind: PropertyRemoved, Breaking: true, Target: target, Message: "property removed"})
+			continue
+		}
+		if ot, nt := schemaType(oldProps.Values[name]), schemaType(n); ot != nt {
+			d.add(Change{Kind: PropertyTypeChanged, Breaking: true, Target: target, Message: fmt.Sprintf("type changed from %s to %s", ot, nt)})
+		}
+		if !oldRequired[name] && newRequired[name] {
+			d.add(Change{Kind: PropertyRequired, Breaking: true, Target: target, Message: "property became required"})
+		}
+		if oldRequired[name] && !newRequired[name] {
+			d.add(Change{Kind: PropertyOptional, Breaking: false, Target: target, Message: "property became optional"})
+		}
+	}
+	for _, name := range newProps.Keys {
+		if _, ok := oldProps.Values[name]; ok {
+			continue
+		}
+		target := model + "." + name
+		if newRequired[name] {
+			d.add(Change{Kind: PropertyAdded, Breaking: true, Target: target, Message: "required property added"})
+		} else {
+			d.add(Change{Kind: PropertyAdded, Target: target, Message: "optional property added"})
+		}
+	}
+}
+
+// properties свойства схемы, пустые если не заданы
+func properties(s *Schema) *Properties {
+	if s == nil || s.Properties == nil {
+		return &Properties{Values: map[string]*Schema{}}
+	}
+	return s.Properties
+}
+
+// required обязательные свойства схемы
+func required(s *Schema) map[string]bool {
+	m := map[string]bool{}
+	if s != nil {
+		for _, name := range s.Required {
+			m[name] = true
+		}
+	}
+	return m
+}
+
+// paramType описание типа параметра: integer(int64), array[string], Person
+func paramType(p *Parameter) string {
+	if p.Schema != nil {
+		return schemaType(p.Schema)
+	}
+	return schemaType(&Schema{Type: p.Type, Format: p.Format, Items: p.Items})
+}
+
+// schemaType описание типа схемы для сравнения
+func schemaType(s *Schema) string {
+	switch {
+	case s == nil:
+		return "none"
+	case s.Ref != "":
+		return strings.TrimPrefix(s.Ref, refDefinitions)
+	case s.Type == "array":
+		return "array[" + schemaType(s.Items) + "]"
+	case s.Format != "":
+		return s.Type + "(" + s.Format + ")"
+	case s.Type == "":
+		return "any"
+	}
+	return s.Type
+}
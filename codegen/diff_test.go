@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"testing"
+)
+
+const oldSpec = `{
+  "swagger": "2.0",
+  "paths": {
+    "pets": {
+      "get": {
+        "parameters": [{"name": "limit", "in": "query", "type": "integer"}],
+        "responses": {"200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}}, "404": {"description": "Not found"}}
+      },
+      "delete": {"responses": {"204": {"description": "Deleted"}}}
+    },
+    "stores": {"get": {"responses": {"200": {"description": "OK"}}}}
+  },
+  "definitions": {
+    "Pet": {"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}, "tag": {"type": "string"}}, "required": ["id"]}
+  }
+}`
+
+const newSpec = `{
+  "swagger": "2.0",
+  "paths": {
+    "pets": {
+      "get": {
+        "parameters": [{"name": "limit", "in": "query", "type": "string"}, {"name": "owner", "in": "query", "type": "string", "required": true}],
+        "responses": {"200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}}, "500": {"description": "Error"}}
+      }
+    },
+    "owners": {"get": {"responses": {"200": {"description": "OK"}}}}
+  },
+  "definitions": {
+    "Pet": {"type": "object", "properties": {"id": {"type": "integer"}, "tag": {"type": "string"}, "age": {"type": "integer"}}, "required": ["id", "tag"]}
+  }
+}`
+
+func TestDiff(t *testing.T) {
+
+	old, err := ParseSpec([]byte(oldSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := ParseSpec([]byte(newSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Kind: OperationRemoved, Breaking: true, Path: "pets", Method: "delete"},
+		{Kind: ParameterTypeChanged, Breaking: true, Path: "pets", Method: "get", Target: "query:limit"},
+		{Kind: ParameterAdded, Breaking: true, Path: "pets", Method: "get", Target: "query:owner"},
+		{Kind: ResponseRemoved, Breaking: true, Path: "pets", Method: "get", Target: "404"},
+		{Kind: ResponseAdded, Path: "pets", Method: "get", Target: "500"},
+		{Kind: PathRemoved, Breaking: true, Path: "stores"},
+		{Kind: PathAdded, Path: "owners"},
+		{Kind: PropertyRemoved, Breaking: true, Target: "Pet.name"},
+		{Kind: PropertyRequired, Breaking: true, Target: "Pet.tag"},
+		{Kind: PropertyAdded, Target: "Pet.age"},
+	}
+
+	report := Diff(old, new)
+	if !report.Breaking {
+		t.Error("report must be breaking")
+	}
+	if len(report.Changes) != len(want) {
+		t.Fatalf("changes %+v", report.Changes)
+	}
+	for i, c := range report.Changes {
+		c.Message = ""
+		if c != want[i] {
+			t.Errorf("change %d: %+v, want %+v", i, c, want[i])
+		}
+	}
+
+	if report = Diff(old, old); report.Breaking || len(report.Changes) != 0 {
+		t.Errorf("same spec %+v", report.Changes)
+	}
+}
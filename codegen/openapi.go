@@ -0,0 +1,155 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+const refComponentsSchemas = "#/components/schemas/"
+
+// openAPI документ OpenAPI 3.x в объеме, необходимом для приведения к swagger 2.0
+type openAPI struct {
+	Info    *Info `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas         map[string]*Schema `json:"schemas"`
+		SecuritySchemes map[string]struct {
+			Type   string `json:"type"`
+			Scheme string `json:"scheme"`
+			Name   string `json:"name"`
+			In     string `json:"in"`
+		} `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	ID          string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Tags        []string              `json:"tags"`
+	Security    []map[string][]string `json:"security"`
+	Parameters  []struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description"`
+		Required    bool    `json:"required"`
+		Schema      *Schema `json:"schema"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Description string             `json:"description"`
+		Required    bool               `json:"required"`
+		Content     map[string]content `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Description string             `json:"description"`
+		Content     map[string]content `json:"content"`
+	} `json:"responses"`
+}
+
+type content struct {
+	Schema *Schema `json:"schema"`
+}
+
+// parseOpenAPI приведение документа OpenAPI 3.x к swagger 2.0
+func parseOpenAPI(b []byte) (*Spec, error) {
+
+	b = bytes.ReplaceAll(b, []byte(`"`+refComponentsSchemas), []byte(`"`+refDefinitions))
+	doc := &openAPI{}
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{
+		Info:                doc.Info,
+		BasePath:            "/",
+		Paths:               map[string]map[string]*Operation{},
+		Definitions:         doc.Components.Schemas,
+		SecurityDefinitions: map[string]SecurityDefinition{},
+	}
+	if len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil {
+			spec.Host = u.Host
+			if u.Scheme != "" {
+				spec.Schemes = []string{u.Scheme}
+			}
+			if u.Path != "" {
+				spec.BasePath = u.Path
+			}
+		}
+	}
+
+	for path, item := range doc.Paths {
+		spec.Paths[path] = map[string]*Operation{}
+		for method, raw := range item {
+			// Кроме операций элемент пути может содержать общие поля
+			if !contains(httpMethods, method) {
+				continue
+			}
+			o := &openAPIOperation{}
+			if err := json.Unmarshal(raw, o); err != nil {
+				return nil, err
+			}
+			op := &Operation{
+				ID:          o.ID,
+				Summary:     o.Summary,
+				Description: o.Description,
+				Tags:        o.Tags,
+				Security:    o.Security,
+				Responses:   map[string]*Response{},
+			}
+			for _, p := range o.Parameters {
+				param := &Parameter{
+					Name:        p.Name,
+					In:          p.In,
+					Description: p.Description,
+					Required:    p.Required,
+				}
+				if p.Schema != nil {
+					param.Type, param.Format, param.Items = p.Schema.Type, p.Schema.Format, p.Schema.Items
+				}
+				op.Parameters = append(op.Parameters, param)
+			}
+			if body := o.RequestBody; body != nil {
+				op.Parameters = append(op.Parameters, &Parameter{
+					Name:        "body",
+					In:          "body",
+					Description: body.Description,
+					Required:    body.Required,
+					Schema:      contentSchema(body.Content),
+				})
+			}
+			for code, r := range o.Responses {
+				op.Responses[code] = &Response{
+					Description: r.Description,
+					Schema:      contentSchema(r.Content),
+				}
+			}
+			spec.Paths[path][method] = op
+		}
+	}
+
+	for name, scheme := range doc.Components.SecuritySchemes {
+		d := SecurityDefinition{Type: scheme.Type, Name: scheme.Name, In: scheme.In}
+		if scheme.Type == "http" {
+			d.Type = scheme.Scheme
+		}
+		spec.SecurityDefinitions[name] = d
+	}
+
+	return spec, nil
+}
+
+// contentSchema схема содержимого application/json, иначе первого по алфавиту типа
+func contentSchema(c map[string]content) *Schema {
+	if v, ok := c["application/json"]; ok {
+		return v.Schema
+	}
+	for _, key := range sortedKeys(c) {
+		return c[key].Schema
+	}
+	return nil
+}
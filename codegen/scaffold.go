@@ -0,0 +1,862 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	p "path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// ScaffoldOptions параметры формирования каркаса
+type ScaffoldOptions struct {
+	// Module путь импорта каталога, в который записываются файлы
+	Module string
+}
+
+// httpMethods методы операций в порядке обхода контроллера ewa.Server
+var httpMethods = []string{"get", "post", "put", "delete", "options", "patch", "head", "connect", "trace"}
+
+var pathParamRegexp = regexp.MustCompile(`{(\w+)}`)
+
+// defaultDescription описание ответа по умолчанию, который ewa добавляет в каждый маршрут
+const defaultDescription = "successful operation"
+
+// Scaffold формирование каркаса сервиса по документу: модели в пакете models,
+// контроллеры в пакетах controllers/..., путь которых совпадает с путем маршрутов,
+// и функция регистрации controllers.Register. Результат - содержимое файлов по
+// относительным путям. Документ, построенный по каркасу, совпадает с исходным
+// по путям, параметрам, ответам и моделям
+func Scaffold(spec *Spec, options ScaffoldOptions) (map[string][]byte, error) {
+
+	if options.Module == "" {
+		return nil, fmt.Errorf("module path is required")
+	}
+
+	s := &scaffolder{
+		spec:     spec,
+		module:   strings.TrimRight(options.Module, "/"),
+		files:    map[string][]byte{},
+		packages: map[string]*scaffoldPackage{},
+		models:   goTypes{models: map[string]string{}},
+		types:    goTypes{models: map[string]string{}},
+	}
+	for _, name := range sortedKeys(spec.Definitions) {
+		s.models.models[name] = exportedName(name)
+		s.types.models[name] = "models." + exportedName(name)
+	}
+
+	if err := s.generateModels(); err != nil {
+		return nil, err
+	}
+	s.collect()
+	for _, dir := range s.sortedPackages() {
+		for _, c := range s.packages[dir].controllers {
+			if err := s.generateController(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.generateRegister(); err != nil {
+		return nil, err
+	}
+
+	return s.files, nil
+}
+
+type scaffolder struct {
+	spec     *Spec
+	module   string
+	files    map[string][]byte
+	packages map[string]*scaffoldPackage
+	// models типы в пакете models, types - в пакетах контроллеров
+	models goTypes
+	types  goTypes
+	// schemes используемые схемы авторизации ewa
+	schemes map[string]*SecurityDefinition
+}
+
+type scaffoldPackage struct {
+	dir         string
+	name        string
+	alias       string
+	names       map[string]bool
+	controllers []*scaffoldController
+}
+
+type scaffoldController struct {
+	pkg      *scaffoldPackage
+	Type     string
+	Path     string
+	register string
+	routes   map[string]*scaffoldRoute
+}
+
+type scaffoldRoute struct {
+	method string
+	rest   string
+	op     *Operation
+	// empty операция без параметров пути, описывается через SetEmptyParam
+	empty *Operation
+}
+
+// use имя, уникальное в пакете
+func (pkg *scaffoldPackage) use(name string) string {
+	unique := name
+	for i := 2; pkg.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	pkg.names[unique] = true
+	return unique
+}
+
+// pkg пакет контроллеров по каталогу
+func (s *scaffolder) pkg(dir string) *scaffoldPackage {
+	if pkg, ok := s.packages[dir]; ok {
+		return pkg
+	}
+	pkg := &scaffoldPackage{
+		dir:   dir,
+		name:  packageName(p.Base(dir)),
+		names: map[string]bool{},
+	}
+	if dir == "controllers" {
+		pkg.names["Register"] = true
+	}
+	s.packages[dir] = pkg
+	return pkg
+}
+
+func (s *scaffolder) sortedPackages() (dirs []string) {
+	for dir := range s.packages {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return
+}
+
+// collect распределение операций по контроллерам. Путь делится на статическую часть,
+// которая задает пакет и имя контроллера, и часть с параметрами пути маршрута
+func (s *scaffolder) collect() {
+
+	basePath := strings.Trim(s.spec.BasePath, "/")
+	type group struct {
+		prefix string
+		rests  map[string]map[string]*Operation
+	}
+	groups := map[string]*group{}
+	var prefixes []string
+
+	for _, path := range sortedKeys(s.spec.Paths) {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		i := 0
+		for i < len(segments) && !strings.Contains(segments[i], "{") {
+			i++
+		}
+		prefix := strings.Join(segments[:i], "/")
+		rest := ""
+		if i < len(segments) {
+			rest = "/" + strings.Join(segments[i:], "/")
+		}
+		g, ok := groups[prefix]
+		if !ok {
+			g = &group{prefix: prefix, rests: map[string]map[string]*Operation{}}
+			groups[prefix] = g
+			prefixes = append(prefixes, prefix)
+		}
+		for method, op := range s.spec.Paths[path] {
+			if g.rests[method] == nil {
+				g.rests[method] = map[string]*Operation{}
+			}
+			g.rests[method][rest] = op
+		}
+	}
+
+	for _, prefix := range prefixes {
+		g := groups[prefix]
+		full := "/" + strings.Trim(p.Join(basePath, prefix), "/")
+
+		// Каталог и имя основного контроллера
+		segments := strings.Split(prefix, "/")
+		last := segments[len(segments)-1]
+		dir := p.Join(append([]string{"controllers", basePath}, segments[:len(segments)-1]...)...)
+		pkg := s.pkg(dir)
+		name := last
+		if name == "" {
+			name = "index"
+		}
+		primary := &scaffoldController{pkg: pkg, Type: pkg.use(exportedName(name)), Path: full, routes: map[string]*scaffoldRoute{}}
+		switch {
+		case last == "":
+			primary.register = fmt.Sprintf(".SetPath(%q)", full)
+		case strings.ToLower(primary.Type) == last:
+		case strings.ToLower(last) == last:
+			primary.register = fmt.Sprintf(".SetName(%q)", last)
+		default:
+			primary.register = fmt.Sprintf(".SetPath(%q)", full)
+		}
+		pkg.controllers = append(pkg.controllers, primary)
+
+		// Маршруты, которые не помещаются в основной контроллер
+		extra := map[string]*scaffoldController{}
+		for _, method := range httpMethods {
+			ops, ok := g.rests[method]
+			if !ok {
+				continue
+			}
+			var rests []string
+			for rest := range ops {
+				rests = append(rests, rest)
+			}
+			sort.Strings(rests)
+
+			route := &scaffoldRoute{method: method, rest: rests[0], op: ops[rests[0]]}
+			rests = rests[1:]
+			if route.rest == "" && len(rests) > 0 && mergeable(ops[""], ops[rests[0]]) {
+				route = &scaffoldRoute{method: method, rest: rests[0], op: ops[rests[0]], empty: ops[""]}
+				rests = rests[1:]
+			}
+			primary.routes[method] = route
+
+			for _, rest := range rests {
+				c, ok := extra[rest]
+				if !ok {
+					c = &scaffoldController{
+						pkg:      pkg,
+						Type:     pkg.use(exportedName(name + rest)),
+						Path:     full,
+						register: fmt.Sprintf(".SetPath(%q)", full),
+						routes:   map[string]*scaffoldRoute{},
+					}
+					extra[rest] = c
+					pkg.controllers = append(pkg.controllers, c)
+				}
+				c.routes[method] = &scaffoldRoute{method: method, rest: rest, op: ops[rest]}
+			}
+		}
+	}
+}
+
+// mergeable операцию без параметров пути можно описать через SetEmptyParam:
+// параметры и авторизация совпадают, ответы включают ответы основной операции
+func mergeable(empty, op *Operation) bool {
+	params := func(o *Operation) string {
+		var list []string
+		for _, p := range o.Parameters {
+			if p.In != "path" {
+				list = append(list, fmt.Sprintf("%s:%s:%t:%s", p.In, p.Name, p.Required, paramType(p)))
+			}
+		}
+		sort.Strings(list)
+		return strings.Join(list, ";")
+	}
+	security := func(o *Operation) string {
+		var list []string
+		for _, sec := range o.Security {
+			for key := range sec {
+				list = append(list, key)
+			}
+		}
+		sort.Strings(list)
+		return strings.Join(list, ";")
+	}
+	if params(empty) != params(op) || security(empty) != security(op) {
+		return false
+	}
+	for code := range op.Responses {
+		if _, ok := empty.Responses[code]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type modelFile struct {
+	Name        string
+	Key         string
+	Description string
+	Type        string
+	Fields      []clientField
+	NeedsTime   bool
+}
+
+// generateModels файл на каждую модель в пакете models
+func (s *scaffolder) generateModels() error {
+
+	for _, name := range sortedKeys(s.spec.Definitions) {
+		schema := s.spec.Definitions[name]
+		s.models.needsTime = false
+		m := modelFile{
+			Name:        s.models.models[name],
+			Key:         name,
+			Description: schema.Description,
+		}
+		if schema.Type != "object" || schema.Properties == nil {
+			m.Type = s.models.goType(schema)
+		} else {
+			for _, key := range schema.Properties.Keys {
+				property := schema.Properties.Values[key]
+				required := contains(schema.Required, key)
+				t := s.models.goType(property)
+				if property.Ref != "" && !required {
+					t = "*" + t
+				}
+				m.Fields = append(m.Fields, clientField{
+					Name: exportedName(key),
+					Type: t,
+					Tag:  modelTag(key, required, property),
+				})
+			}
+		}
+		m.NeedsTime = s.models.needsTime
+
+		var buf bytes.Buffer
+		if err := modelTemplate.Execute(&buf, m); err != nil {
+			return err
+		}
+		if err := s.write(p.Join("models", strings.ToLower(m.Name)+".go"), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// modelTag тэги поля модели: имя json, формат и описание JSON Schema
+func modelTag(key string, required bool, s *Schema) string {
+	tag := key
+	if !required {
+		tag += ",omitempty"
+	}
+	tags := []string{"json:" + strconv.Quote(tag)}
+	if s.Format != "" && s.Format != "date-time" && s.Type == "string" {
+		tags = append(tags, "jsonschema:"+strconv.Quote("format="+s.Format))
+	}
+	if s.Description != "" {
+		tags = append(tags, "jsonschema_description:"+strconv.Quote(s.Description))
+	}
+	return structTag(strings.Join(tags, " "))
+}
+
+// structTag литерал тэга поля
+func structTag(tag string) string {
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+type controllerFile struct {
+	Package string
+	Imports []string
+	*scaffoldController
+	Routes []routeCode
+}
+
+type routeCode struct {
+	Method  string
+	Path    string
+	Comment string
+	Calls   string
+	In      string
+	Fields  []clientField
+	Todo    []string
+}
+
+// generateController файл контроллера с методами маршрутов
+func (s *scaffolder) generateController(c *scaffoldController) error {
+
+	f := controllerFile{Package: c.pkg.name, scaffoldController: c}
+	s.types.needsTime = false
+	imports := map[string]bool{`ewa "github.com/egovorukhin/egowebapi"`: true}
+
+	for _, method := range httpMethods {
+		route, ok := c.routes[method]
+		if !ok {
+			continue
+		}
+		code := s.route(c, route, imports)
+		f.Routes = append(f.Routes, code)
+	}
+	if s.types.needsTime {
+		imports[`"time"`] = true
+	}
+	for imp := range imports {
+		f.Imports = append(f.Imports, imp)
+	}
+	sort.Strings(f.Imports)
+
+	var buf bytes.Buffer
+	if err := controllerTemplate.Execute(&buf, f); err != nil {
+		return err
+	}
+	return s.write(p.Join(c.pkg.dir, strings.ToLower(c.Type)+".go"), buf.Bytes())
+}
+
+// route описание маршрута вызовами методов ewa.Route
+func (s *scaffolder) route(c *scaffoldController, r *scaffoldRoute, imports map[string]bool) routeCode {
+
+	op := r.op
+	code := routeCode{
+		Method:  exportedName(r.method),
+		Path:    strings.ToUpper(r.method) + " " + strings.TrimPrefix(c.Path+r.rest, "/"),
+		Comment: strings.TrimSpace(op.Summary),
+	}
+	var calls, params []string
+
+	// Авторизация
+	var sec []string
+	for _, item := range op.Security {
+		for key := range item {
+			if name := s.security(key); name != "" && !contains(sec, name) {
+				sec = append(sec, name)
+			}
+		}
+	}
+	sort.Strings(sec)
+	if len(sec) > 0 {
+		imports[`"github.com/egovorukhin/egowebapi/security"`] = true
+		calls = append(calls, "SetSecurity(security."+strings.Join(sec, ", security.")+")")
+	}
+
+	// Параметры пути: часть пути до параметра включительно, остаток пути
+	// после последнего параметра относится к нему же
+	byName := map[string]*Parameter{}
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			byName[param.Name] = param
+		}
+	}
+	matches := pathParamRegexp.FindAllStringSubmatchIndex(r.rest, -1)
+	start := 0
+	for i, m := range matches {
+		end := m[1]
+		if i == len(matches)-1 {
+			end = len(r.rest)
+		}
+		name := r.rest[m[2]:m[3]]
+		param := byName[name]
+		if param == nil {
+			param = &Parameter{Name: name, In: "path", Type: "string"}
+		}
+		params = append(params, "ewa.NewPathParam("+quoteArgs(r.rest[start:end], param.Description)+")"+typeSetters(param, "string"))
+		start = end
+	}
+
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "query":
+			if param.Type == "array" && param.Items != nil {
+				params = append(params, "ewa.NewQueryArrayParam("+strconv.Quote(param.Name)+", "+strconv.Quote(param.Items.Type)+", "+strconv.FormatBool(param.Required)+descArg(param.Description)+")")
+				break
+			}
+			params = append(params, "ewa.NewQueryParam("+strconv.Quote(param.Name)+", "+strconv.FormatBool(param.Required)+descArg(param.Description)+")"+typeSetters(param, "string"))
+		case "header":
+			params = append(params, "ewa.NewHeaderParam("+strconv.Quote(param.Name)+", "+strconv.FormatBool(param.Required)+descArg(param.Description)+")"+typeSetters(param, "string"))
+		case "formData":
+			params = append(params, "ewa.NewFormDataParam("+strconv.Quote(param.Name)+", "+strconv.Quote(param.Type)+", "+strconv.FormatBool(param.Required)+descArg(param.Description)+")"+typeSetters(param, param.Type))
+		case "body":
+			model, isArray := s.modelRef(param.Schema)
+			if model == "" {
+				code.Todo = append(code.Todo, "тело запроса без модели в определениях не описано")
+				break
+			}
+			imports[strconv.Quote(s.module+"/models")] = true
+			params = append(params, "ewa.NewBodyParam("+strconv.FormatBool(param.Required)+", "+model+", "+strconv.FormatBool(isArray)+descArg(param.Description)+")")
+		}
+	}
+	if len(params) > 0 {
+		calls = append(calls, "SetParameters("+strings.Join(params, ", ")+")")
+	}
+
+	if op.Summary != "" {
+		calls = append(calls, "SetSummary("+strconv.Quote(op.Summary)+")")
+	}
+	if op.Description != "" {
+		calls = append(calls, "SetDescription("+strconv.Quote(op.Description)+")")
+	}
+	calls = append(calls, s.responses(op.Responses, true, imports, &code)...)
+
+	// Операция без параметров пути
+	if e := r.empty; e != nil {
+		empty := "SetEmptyParam(" + strconv.Quote(e.Summary)
+		if e.Description != "" {
+			empty += ", " + strconv.Quote(e.Description)
+		}
+		empty += ")"
+		for _, call := range s.responses(e.Responses, false, imports, &code) {
+			empty += "." + call
+		}
+		calls = append(calls, empty)
+	}
+	code.Calls = strings.Join(calls, ".\n\t\t")
+
+	// Входная модель обработчика
+	names := map[string]bool{}
+	for _, param := range op.Parameters {
+		var tag string
+		switch param.In {
+		case "path":
+			tag = "path:name=" + param.Name
+		case "query", "header":
+			tag = param.In + ":name=" + param.Name
+			if param.Required {
+				tag = param.In + ":required,name=" + param.Name
+			}
+		case "body":
+			if model, _ := s.modelRef(param.Schema); model == "" {
+				continue
+			}
+			tag = "body:name=body"
+			if param.Required {
+				tag = "body:required"
+			}
+		default:
+			continue
+		}
+		name := exportedName(param.Name)
+		for names[name] {
+			name += exportedName(param.In)
+		}
+		names[name] = true
+		t := s.types.goType(&Schema{Type: param.Type, Format: param.Format, Items: param.Items})
+		if param.Schema != nil {
+			t = s.types.goType(param.Schema)
+		}
+		code.Fields = append(code.Fields, clientField{Name: name, Type: t, Tag: structTag("ewa:" + strconv.Quote(tag))})
+	}
+	if len(code.Fields) > 0 {
+		code.In = c.pkg.use(c.Type + code.Method + "In")
+	}
+	imports[`"github.com/egovorukhin/egowebapi/consts"`] = true
+
+	return code
+}
+
+// responses вызовы SetResponse для ответов операции
+func (s *scaffolder) responses(responses map[string]*Response, isRoute bool, imports map[string]bool, code *routeCode) (calls []string) {
+	for _, status := range sortedKeys(responses) {
+		r := responses[status]
+		model, isArray := s.modelRef(r.Schema)
+		if model == "" {
+			model = `""`
+		} else {
+			imports[strconv.Quote(s.module+"/models")] = true
+		}
+		desc := descArg(r.Description)
+		if status == "default" {
+			// Ответ по умолчанию ewa добавляет в каждый маршрут
+			if model == `""` && r.Description == defaultDescription {
+				continue
+			}
+			if !isRoute {
+				code.Todo = append(code.Todo, "ответ default операции без параметров пути не описан")
+				continue
+			}
+			calls = append(calls, "SetDefaultResponse("+model+", "+strconv.FormatBool(isArray)+", nil"+desc+")")
+			continue
+		}
+		if _, err := strconv.Atoi(status); err != nil {
+			continue
+		}
+		name := "SetResponse"
+		if isArray {
+			name = "SetResponseArray"
+		}
+		calls = append(calls, name+"("+status+", "+model+", nil"+desc+")")
+	}
+	return
+}
+
+// modelRef константа имени модели для схемы со ссылкой или массива ссылок
+func (s *scaffolder) modelRef(schema *Schema) (string, bool) {
+	if schema == nil {
+		return "", false
+	}
+	isArray := false
+	if schema.Type == "array" && schema.Items != nil {
+		schema, isArray = schema.Items, true
+	}
+	name, ok := s.models.models[strings.TrimPrefix(schema.Ref, refDefinitions)]
+	if schema.Ref == "" || !ok {
+		return "", false
+	}
+	return "models.Model" + name, isArray
+}
+
+// security имя схемы авторизации ewa для схемы документа
+func (s *scaffolder) security(key string) string {
+	d := s.spec.SecurityDefinitions[key]
+	var name string
+	switch {
+	case key == "Basic" || key == "ApiKey" || key == "OAuth2":
+		name = key
+	case d.Type == "basic" && d.Scheme == "":
+		name = "Basic"
+	case d.Type == "apiKey":
+		name = "ApiKey"
+	case d.Type == "oauth2":
+		name = "OAuth2"
+	default:
+		return ""
+	}
+	if s.schemes == nil {
+		s.schemes = map[string]*SecurityDefinition{}
+	}
+	if _, ok := s.schemes[name]; !ok {
+		s.schemes[name] = &d
+	}
+	return name + "Auth"
+}
+
+type registerFile struct {
+	Imports     []string
+	Info        *Info
+	Host        string
+	BasePath    string
+	Models      []modelFile
+	Schemes     map[string]*SecurityDefinition
+	Controllers []string
+}
+
+// generateRegister функция регистрации моделей и контроллеров
+func (s *scaffolder) generateRegister() error {
+
+	f := registerFile{
+		Info:     s.spec.Info,
+		Host:     s.spec.Host,
+		BasePath: s.spec.BasePath,
+		Schemes:  s.schemes,
+	}
+	imports := map[string]bool{`ewa "github.com/egovorukhin/egowebapi"`: true}
+	for _, name := range sortedKeys(s.spec.Definitions) {
+		imports[strconv.Quote(s.module+"/models")] = true
+		f.Models = append(f.Models, modelFile{Name: s.models.models[name]})
+	}
+	if len(s.schemes) > 0 {
+		imports[`"github.com/egovorukhin/egowebapi/security"`] = true
+	}
+	if _, ok := s.schemes["ApiKey"]; ok {
+		imports[`"errors"`] = true
+	}
+
+	// Псевдонимы пакетов с одинаковыми именами
+	aliases := map[string]bool{"ewa": true, "models": true, "security": true, "errors": true}
+	for _, dir := range s.sortedPackages() {
+		pkg := s.packages[dir]
+		if dir == "controllers" {
+			continue
+		}
+		alias := pkg.name
+		for i := 2; aliases[alias]; i++ {
+			alias = pkg.name + strconv.Itoa(i)
+		}
+		aliases[alias] = true
+		pkg.alias = alias
+		path := strconv.Quote(s.module + "/" + dir)
+		if alias != p.Base(dir) {
+			path = alias + " " + path
+		}
+		imports[path] = true
+	}
+	for imp := range imports {
+		f.Imports = append(f.Imports, imp)
+	}
+	sort.Strings(f.Imports)
+
+	for _, dir := range s.sortedPackages() {
+		pkg := s.packages[dir]
+		for _, c := range pkg.controllers {
+			name := c.Type
+			if pkg.alias != "" {
+				name = pkg.alias + "." + name
+			}
+			f.Controllers = append(f.Controllers, "ws.Register(new("+name+"))"+c.register)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := registerTemplate.Execute(&buf, f); err != nil {
+		return err
+	}
+	return s.write("controllers/register.go", buf.Bytes())
+}
+
+// write форматирование и сохранение файла
+func (s *scaffolder) write(name string, b []byte) error {
+	src, err := format.Source(b)
+	if err != nil {
+		return fmt.Errorf("format %s: %s\n%s", name, err, b)
+	}
+	s.files[name] = src
+	return nil
+}
+
+// packageName имя пакета по имени каталога
+func packageName(dir string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(dir) {
+		if unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0) {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || token.Lookup(name).IsKeyword() {
+		name += "pkg"
+	}
+	return name
+}
+
+// quoteArgs первый аргумент и необязательное описание
+func quoteArgs(s, desc string) string {
+	return strconv.Quote(s) + descArg(desc)
+}
+
+func descArg(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	return ", " + strconv.Quote(desc)
+}
+
+// typeSetters вызовы SetType и SetFormat, если тип отличается от типа по умолчанию
+func typeSetters(param *Parameter, def string) (s string) {
+	if param.Type != "" && param.Type != def {
+		s += ".SetType(" + strconv.Quote(param.Type) + ")"
+	}
+	if param.Format != "" {
+		s += ".SetFormat(" + strconv.Quote(param.Format) + ")"
+	}
+	return
+}
+
+var modelTemplate = template.Must(template.New("model").Funcs(template.FuncMap{
+	"lines": func(s string) []string { return strings.Split(strings.TrimSpace(s), "\n") },
+}).Parse(`package models
+{{if .NeedsTime}}
+import "time"
+{{end}}
+// Model{{.Name}} имя модели в документе swagger
+const Model{{.Name}} = {{printf "%q" .Key}}
+{{if .Description}}
+{{range lines .Description}}// {{.}}
+{{end}}
+{{- else}}
+// {{.Name}} модель {{.Key}}
+{{- end}}
+{{- if .Type}}
+type {{.Name}} {{.Type}}
+{{else}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+{{end}}`))
+
+var controllerTemplate = template.Must(template.New("controller").Parse(`package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// {{.Type}} контроллер {{.Path}}
+type {{.Type}} struct{}
+{{range .Routes}}
+{{- if .In}}
+// {{.In}} параметры запроса {{.Path}}
+type {{.In}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+{{end}}
+// {{.Method}} {{if .Comment}}{{.Comment}}{{else}}{{.Path}}{{end}}
+func ({{$.Type}}) {{.Method}}(route *ewa.Route) {
+{{- range .Todo}}
+
+	// TODO: {{.}}
+{{- end}}
+{{if .Calls}}
+	route.{{.Calls}}
+{{end}}
+	route.Handler = func(c *ewa.Context) error {
+{{- if .In}}
+		in := &{{.In}}{}
+		if err := c.Bind(in); err != nil {
+			return err
+		}
+{{- end}}
+		// TODO: реализовать {{.Path}}
+		return ewa.NewError(consts.StatusNotImplemented)
+	}
+}
+{{end}}`))
+
+var registerTemplate = template.Must(template.New("register").Parse(`package controllers
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// Register регистрация моделей и контроллеров
+func Register(ws *ewa.Server) {
+{{if .Info}}
+	ws.Swagger.SetInfo({{printf "%q" .Host}}, &ewa.Info{
+		Title:       {{printf "%q" .Info.Title}},
+		Description: {{printf "%q" .Info.Description}},
+		Version:     {{printf "%q" .Info.Version}},
+	}, nil)
+{{- end}}
+	ws.Swagger.SetBasePath({{printf "%q" .BasePath}})
+{{- if .Models}}
+	ws.Swagger.SetModels(ewa.Models{
+{{- range .Models}}
+		models.Model{{.Name}}: models.{{.Name}}{},
+{{- end}}
+	})
+{{- end}}
+{{if .Schemes}}
+	// TODO: проверка учетных данных
+	auth := &ws.Config.Authorization
+{{- with .Schemes.Basic}}
+	if auth.Basic == nil {
+		auth.Basic = &security.Basic{
+			Handler: func(user string, pass string) bool {
+				return false
+			},
+		}
+	}
+{{- end}}
+{{- with .Schemes.ApiKey}}
+	if auth.ApiKey == nil {
+		auth.ApiKey = &security.ApiKey{
+			KeyName: {{printf "%q" .Name}},
+			Param:   {{if eq .In "query"}}security.ParamQuery{{else}}security.ParamHeader{{end}},
+			Handler: func(token string) (string, error) {
+				return "", errors.New("not implemented")
+			},
+		}
+	}
+{{- end}}
+{{- if .Schemes.OAuth2}}
+	if auth.OAuth2 == nil {
+		auth.OAuth2 = &security.OAuth2{}
+	}
+{{- end}}
+{{end}}
+{{- range .Controllers}}
+	{{.}}
+{{- end}}
+}
+`))
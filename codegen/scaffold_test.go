@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"fmt"
+	ewa "github.com/egovorukhin/egowebapi"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+type Owners struct{}
+
+func (Owners) Get(route *ewa.Route) {
+	route.SetParameters(ewa.NewPathParam("/{id}").SetType("integer"), ewa.NewQueryParam("fields", false)).
+		SetResponse(200, "Owner", nil, "Owner").
+		SetResponse(404, "", nil, "Not found").
+		SetEmptyParam("List owners").SetResponseArray(200, "Owner", nil, "Owners").SetResponse(404, "", nil, "Not found")
+	route.Handler = func(c *ewa.Context) error {
+		return nil
+	}
+}
+
+type Visits struct{}
+
+func (Visits) Get(route *ewa.Route) {
+	route.SetParameters(ewa.NewPathParam("/{id}"), ewa.NewPathParam("/visits/{visit}", "Visit")).
+		SetResponse(204, "", nil, "No content")
+	route.Handler = func(c *ewa.Context) error {
+		return nil
+	}
+}
+
+const scaffoldMain = `package main
+
+import (
+	"fmt"
+	ewa "github.com/egovorukhin/egowebapi"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"os"
+	reg "%s/controllers"
+)
+
+func main() {
+	ws := ewa.New(&nethttp.Server{}, ewa.Config{
+		ContextHandler: func(handler ewa.Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(ewa.NewContext(c))
+			})
+		},
+	})
+	reg.Register(ws)
+	if err := ws.Build(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	b, err := ws.Swagger.JSON()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(b)
+}
+`
+
+func TestScaffold(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("builds generated code")
+	}
+
+	original := buildSpec(t, func(ws *ewa.Server) {
+		ws.Swagger.SetModel("Owner", Owner{})
+		ws.Register(new(Pets)).SetPath("/api/pets")
+		ws.Register(new(Owners)).SetPath("/api/clinic/owners")
+		ws.Register(new(Visits)).SetPath("/api/clinic/owners")
+	})
+
+	// Каталог внутри модуля, чтобы сгенерированные пакеты импортировали ewa
+	dir, err := ioutil.TempDir(".", "_scaffold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	module := "github.com/egovorukhin/egowebapi/codegen/" + filepath.Base(dir)
+
+	files, err := Scaffold(original, ScaffoldOptions{Module: module})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"models/pet.go",
+		"controllers/register.go",
+		"controllers/api/pets.go",
+		"controllers/api/clinic/owners.go",
+		"controllers/api/clinic/ownersidvisitsvisit.go",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("file %s is not generated", name)
+		}
+	}
+	files["main/main.go"] = []byte(fmt.Sprintf(scaffoldMain, module))
+	for name, b := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(name, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir)+"/main")
+	cmd.Stderr = os.Stderr
+	b, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip, err := ParseSpec(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report := Diff(original, roundTrip); len(report.Changes) > 0 {
+		t.Errorf("round trip changes %+v", report.Changes)
+	}
+}
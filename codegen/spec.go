@@ -0,0 +1,239 @@
+// Package codegen формирование кода и сравнение версий документа swagger 2.0, который строит ewa.Server
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const refDefinitions = "#/definitions/"
+
+// Spec документ swagger 2.0 в объеме, необходимом для генерации
+type Spec struct {
+	Info                *Info                            `json:"info"`
+	Host                string                           `json:"host"`
+	BasePath            string                           `json:"basePath"`
+	Schemes             []string                         `json:"schemes"`
+	Paths               map[string]map[string]*Operation `json:"paths"`
+	Definitions         map[string]*Schema               `json:"definitions"`
+	SecurityDefinitions map[string]SecurityDefinition    `json:"securityDefinitions"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type Operation struct {
+	ID          string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Tags        []string              `json:"tags"`
+	Parameters  []*Parameter          `json:"parameters"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Type        string  `json:"type"`
+	Format      string  `json:"format"`
+	Items       *Schema `json:"items"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+type SecurityDefinition struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	In   string `json:"in"`
+	// Scheme расширение x-scheme для схем HTTP, которых нет в swagger 2.0 (digest)
+	Scheme string `json:"x-scheme"`
+}
+
+// Schema схема JSON Schema, свойства сохраняют порядок объявления
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Items                *Schema            `json:"items"`
+	Properties           *Properties        `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	PatternProperties    map[string]*Schema `json:"patternProperties"`
+}
+
+// UnmarshalJSON схема может быть задана значением true/false,
+// тип в OpenAPI 3.1 может быть списком вида ["string", "null"]
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && (b[0] == 't' || b[0] == 'f') {
+		*s = Schema{}
+		return nil
+	}
+	type schema Schema
+	aux := struct {
+		*schema
+		Type json.RawMessage `json:"type"`
+	}{schema: (*schema)(s)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if len(aux.Type) == 0 {
+		return nil
+	}
+	if aux.Type[0] != '[' {
+		return json.Unmarshal(aux.Type, &s.Type)
+	}
+	var types []string
+	if err := json.Unmarshal(aux.Type, &types); err != nil {
+		return err
+	}
+	for _, t := range types {
+		if t != "null" {
+			s.Type = t
+			break
+		}
+	}
+	return nil
+}
+
+// Properties свойства объекта в порядке объявления
+type Properties struct {
+	Keys   []string
+	Values map[string]*Schema
+}
+
+func (p *Properties) UnmarshalJSON(b []byte) error {
+
+	p.Values = map[string]*Schema{}
+	if err := json.Unmarshal(b, &p.Values); err != nil {
+		return err
+	}
+
+	// Порядок ключей
+	d := json.NewDecoder(bytes.NewReader(b))
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		p.Keys = append(p.Keys, t.(string))
+		var skip json.RawMessage
+		if err = d.Decode(&skip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseSpec разбор документа swagger 2.0, документ OpenAPI 3.x приводится к swagger 2.0
+func ParseSpec(b []byte) (*Spec, error) {
+
+	var version struct {
+		Swagger string `json:"swagger"`
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(b, &version); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(version.OpenAPI, "3.") {
+		return parseOpenAPI(b)
+	}
+	if version.Swagger != "2.0" {
+		return nil, fmt.Errorf("unsupported document version %q, want swagger 2.0 or openapi 3.x", version.Swagger+version.OpenAPI)
+	}
+
+	spec := &Spec{}
+	if err := json.Unmarshal(b, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// sortedKeys ключи в алфавитном порядке для стабильного результата
+func sortedKeys(m interface{}) (keys []string) {
+	switch v := m.(type) {
+	case map[string]map[string]*Operation:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*Operation:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*Schema:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*Response:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]content:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return
+}
+
+// exportedName имя экспортируемого идентификатора: get-storage-user-{id} -> GetStorageUserId
+func exportedName(s string) string {
+
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteByte('X')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+// reserved имена переменных сгенерированных методов
+var reserved = map[string]bool{
+	"c": true, "ctx": true, "params": true, "body": true, "path": true,
+	"query": true, "header": true, "out": true, "err": true,
+}
+
+// localName имя аргумента функции
+func localName(s string) string {
+	name := []rune(exportedName(s))
+	name[0] = unicode.ToLower(name[0])
+	s = string(name)
+	if token.Lookup(s).IsKeyword() || reserved[s] {
+		s += "Param"
+	}
+	return s
+}
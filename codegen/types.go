@@ -0,0 +1,66 @@
+package codegen
+
+import (
+	"strings"
+)
+
+// goTypes соответствие схем типам Go
+type goTypes struct {
+	// models имена типов Go для определений
+	models    map[string]string
+	needsTime bool
+}
+
+// paramType тип простого параметра
+func (g *goTypes) paramType(p *Parameter) string {
+	return g.goType(&Schema{Type: p.Type, Format: p.Format, Items: p.Items})
+}
+
+// goType тип Go для схемы
+func (g *goTypes) goType(s *Schema) string {
+
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		if name, ok := g.models[strings.TrimPrefix(s.Ref, refDefinitions)]; ok {
+			return name
+		}
+		return "json.RawMessage"
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.needsTime = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		switch s.Format {
+		case "int8", "int16", "int32", "int64":
+			return s.Format
+		}
+		return "int"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+		if len(s.PatternProperties) == 1 {
+			for _, value := range s.PatternProperties {
+				return "map[string]" + g.goType(value)
+			}
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
@@ -4,19 +4,24 @@ import (
 	"github.com/egovorukhin/egowebapi/security"
 	"github.com/egovorukhin/egowebapi/session"
 	"path/filepath"
+	"time"
 )
 
 type Config struct {
-	Port           int
-	Secure         *Secure
-	Authorization  security.Authorization
-	Session        *session.Config
-	Permission     *Permission
-	Static         *Static
-	NotFoundPage   string
-	Views          *Views
-	ContextHandler ContextHandler
-	ErrorHandler   ErrorHandler
+	Port            int
+	ShutdownTimeout time.Duration
+	Secure          *Secure
+	Authorization   security.Authorization
+	Session         *session.Config
+	Permission      *Permission
+	Validation      bool
+	Static          *Static
+	NotFoundPage    string
+	Views           *Views
+	Docs            *Docs
+	StrictSwagger   bool
+	ContextHandler  ContextHandler
+	ErrorHandler    ErrorHandler
 }
 
 type Views struct {
@@ -37,9 +42,19 @@ type Secure struct {
 }
 
 type Handler func(c *Context) error
+
+// Middleware промежуточный обработчик. Цепочка собирается в порядке: сервер, контроллер, маршрут,
+// внутри каждого уровня первый добавленный выполняется первым. Вся цепочка оборачивает
+// проверку авторизации, сессии и прав доступа маршрута
+type Middleware func(next Handler) Handler
 type ContextHandler func(handler Handler) interface{}
 type PermissionHandler func(username string, path string) bool
+type IdentityPermissionHandler func(identity *security.Identity, path string) bool
 type ErrorHandler func(c *Context, statusCode int, err interface{}) error
+type Hook func(s *Server) error
+
+// DefaultShutdownTimeout время на плавную остановку сервера по умолчанию
+const DefaultShutdownTimeout = 10 * time.Second
 
 func (s *Secure) Get() (cert string, key string) {
 	key = filepath.Join(s.Path, s.Key)
@@ -6,7 +6,6 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 type Context struct {
@@ -15,13 +14,10 @@ type Context struct {
 	Session  *Session
 	//View     *View
 	IContext
-}
-
-type Session struct {
-	Key      string
-	Value    string
-	Created  time.Time
-	LastTime time.Time
+	// commit запись сессии в cookie перед отправкой ответа
+	commit func() error
+	// loadSession отложенная загрузка сессии для маршрута без проверки сессии
+	loadSession func() *Session
 }
 
 type View struct {
@@ -46,6 +42,8 @@ type IContext interface {
 	ClearCookie(key string)
 	Redirect(location string, status int) error
 	Path() string
+	Method() string
+	RequestURI() string
 	JSON(code int, data interface{}) error
 	Body() []byte
 	BodyParser(out interface{}) error
@@ -43,15 +43,18 @@ type IConnect interface {
 }
 
 type Controller struct {
-	Interface interface{}
-	IsShow    bool
-	Name      string
-	Path      string
-	Suffix    []Suffix
-	PathTree  []string
-	FileTree  []string
-	Tag       Tag
-	Models    Models
+	Interface  interface{}
+	IsShow     bool
+	Name       string
+	Path       string
+	Suffix     []Suffix
+	PathTree   []string
+	FileTree   []string
+	Tag        Tag
+	Models     Models
+	middleware []Middleware
+	roles      []string
+	scopes     []string
 }
 
 // SetName Устанавливаем имя контроллера
@@ -88,6 +91,25 @@ func (c *Controller) SetSuffix(suffix ...Suffix) *Controller {
 	return c
 }
 
+// Use Добавить промежуточные обработчики для всех маршрутов контроллера
+func (c *Controller) Use(middleware ...Middleware) *Controller {
+	c.middleware = append(c.middleware, middleware...)
+	return c
+}
+
+// RequireRoles Указать роли для всех маршрутов контроллера, см. Route.RequireRoles.
+// Роли маршрута не заменяют роли контроллера, необходима роль из каждого списка
+func (c *Controller) RequireRoles(roles ...string) *Controller {
+	c.roles = appendUnique(c.roles, roles...)
+	return c
+}
+
+// RequireScopes Указать области доступа для всех маршрутов контроллера, см. Route.RequireScopes
+func (c *Controller) RequireScopes(scopes ...string) *Controller {
+	c.scopes = appendUnique(c.scopes, scopes...)
+	return c
+}
+
 // NotShow Установка флага отображения контроллера в swagger
 func (c *Controller) NotShow() *Controller {
 	c.IsShow = false
@@ -167,3 +189,13 @@ func insert(a []string, index int, value string) []string {
 	a[index] = value
 	return a
 }
+
+// appendUnique добавить значения, которых еще нет в списке
+func appendUnique(a []string, values ...string) []string {
+	for _, value := range values {
+		if !contains(a, value) {
+			a = append(a, value)
+		}
+	}
+	return a
+}
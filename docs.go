@@ -0,0 +1,147 @@
+package egowebapi
+
+import (
+	"bytes"
+	"github.com/egovorukhin/egowebapi/consts"
+	"github.com/egovorukhin/egowebapi/docs"
+	"gopkg.in/yaml.v2"
+	p "path"
+	"strings"
+)
+
+// Docs публикация документации: {Path}/swagger.json, {Path}/swagger.yaml,
+// страница Swagger UI {Path}/swagger и страница ReDoc {Path}/redoc со встроенным скриптом
+type Docs struct {
+	// Path префикс маршрутов документации
+	Path string
+	// Title заголовок страниц
+	Title string
+	// Security схемы авторизации для доступа к документации
+	Security []string
+	// RedocScript внешний адрес скрипта ReDoc вместо встроенного docs.RedocScript
+	RedocScript string
+}
+
+const MIMEApplicationYAML = "application/yaml"
+
+// swaggerAssets файлы Swagger UI из встроенных ресурсов
+var swaggerAssets = map[string]string{
+	"swagger-ui-bundle.js": consts.MIMEApplicationJavaScriptCharsetUTF8,
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"favicon-32x32.png":    "image/png",
+}
+
+// addDocs регистрация маршрутов документации. Маршруты проходят через те же
+// проверки авторизации и промежуточные обработчики, что и маршруты контроллеров
+func (s *Server) addDocs() error {
+
+	d := s.Config.Docs
+	title := d.Title
+	if title == "" && s.Swagger.Info != nil {
+		title = s.Swagger.Info.Title
+	}
+	prefix := p.Join("/", d.Path)
+	page := docs.Page{
+		Title:   title,
+		SpecURL: p.Join(prefix, "swagger.json"),
+		Assets:  p.Join(prefix, "swagger"),
+		Script:  d.RedocScript,
+	}
+
+	handlers := map[string]Handler{
+		"swagger.json": func(c *Context) error {
+			b, err := s.requestSwagger(c).JSON()
+			if err != nil {
+				return err
+			}
+			return c.Send(consts.StatusOK, consts.MIMEApplicationJSONCharsetUTF8, b)
+		},
+		"swagger.yaml": func(c *Context) error {
+			b, err := s.requestSwagger(c).YAML()
+			if err != nil {
+				return err
+			}
+			return c.Send(consts.StatusOK, MIMEApplicationYAML, b)
+		},
+		"swagger": func(c *Context) error {
+			var buf bytes.Buffer
+			if err := docs.SwaggerUI(&buf, page); err != nil {
+				return err
+			}
+			return c.Send(consts.StatusOK, consts.MIMETextHTMLCharsetUTF8, buf.Bytes())
+		},
+	}
+
+	// Страница ReDoc со встроенным скриптом или с указанным адресом
+	handlers["redoc"] = func(c *Context) error {
+		var buf bytes.Buffer
+		if err := docs.ReDoc(&buf, page); err != nil {
+			return err
+		}
+		return c.Send(consts.StatusOK, consts.MIMETextHTMLCharsetUTF8, buf.Bytes())
+	}
+	if redocScript, err := docs.Assets.ReadFile("assets/" + docs.RedocScript); err == nil {
+		handlers["swagger/"+docs.RedocScript] = func(c *Context) error {
+			return c.Send(consts.StatusOK, consts.MIMEApplicationJavaScriptCharsetUTF8, redocScript)
+		}
+	}
+
+	for name, contentType := range swaggerAssets {
+		b, err := docs.Assets.ReadFile("assets/" + name)
+		if err != nil {
+			return err
+		}
+		contentType := contentType
+		handlers["swagger/"+name] = func(c *Context) error {
+			return c.Send(consts.StatusOK, contentType, b)
+		}
+	}
+
+	for name, handler := range handlers {
+		route := s.newRoute()
+		route.SetSecurity(d.Security...).SetHandler(handler)
+		c := &Controller{
+			Name: "Docs",
+			Path: p.Join(prefix, name),
+		}
+		if err := s.add(consts.MethodGet, c, route); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requestSwagger копия документа с хостом и схемой текущего запроса
+func (s *Server) requestSwagger(c *Context) *Swagger {
+
+	swagger := *s.Swagger
+	if host := c.Hostname(); host != "" {
+		swagger.Host = host
+	}
+	scheme := c.Scheme()
+	if proto := c.Get(consts.HeaderXForwardedProto); proto != "" {
+		scheme = strings.ToLower(strings.Split(proto, ",")[0])
+	}
+	if scheme != "" {
+		swagger.Schemes = []string{scheme}
+	}
+
+	return &swagger
+}
+
+// YAML Преобразование в yaml в формате выбранной версии
+func (s *Swagger) YAML() ([]byte, error) {
+
+	b, err := s.JSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON является подмножеством YAML, MapSlice сохраняет порядок ключей
+	var doc yaml.MapSlice
+	if err = yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
@@ -0,0 +1,88 @@
+// Package docs содержит встроенные в бинарный файл страницы Swagger UI и ReDoc.
+// Файлы Swagger UI (Apache License 2.0, assets/swagger-ui-bundle.js.LICENSE.txt) взяты
+// из дистрибутива swagger-ui/dist, скрипт ReDoc (MIT, assets/redoc.LICENSE) версии
+// RedocVersion хранится в assets и обновляется командой go generate
+package docs
+
+//go:generate go run gen.go
+
+import (
+	"embed"
+	"html/template"
+	"io"
+)
+
+// Assets статические файлы Swagger UI и ReDoc
+//
+//go:embed assets
+var Assets embed.FS
+
+// RedocVersion версия встроенного скрипта ReDoc
+const RedocVersion = "2.1.3"
+
+// RedocScript имя встроенного скрипта ReDoc в Assets
+const RedocScript = "redoc.standalone.js"
+
+// Page параметры страницы документации
+type Page struct {
+	Title   string
+	SpecURL string
+	Assets  string
+	Script  string
+}
+
+var swaggerUI = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<title>{{.Title}}</title>
+	<link rel="stylesheet" type="text/css" href="{{.Assets}}/swagger-ui.css">
+	<link rel="icon" type="image/png" href="{{.Assets}}/favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.Assets}}/swagger-ui-bundle.js" charset="UTF-8"></script>
+<script>
+	window.onload = function () {
+		window.ui = SwaggerUIBundle({
+			url: "{{.SpecURL}}",
+			dom_id: "#swagger-ui",
+			deepLinking: true,
+			presets: [SwaggerUIBundle.presets.apis],
+			layout: "BaseLayout"
+		});
+	};
+</script>
+</body>
+</html>
+`))
+
+var redoc = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{.Title}}</title>
+	<link rel="icon" type="image/png" href="{{.Assets}}/favicon-32x32.png" sizes="32x32">
+	<style>body { margin: 0; padding: 0; }</style>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="{{.Script}}"></script>
+</body>
+</html>
+`))
+
+// SwaggerUI формирование страницы Swagger UI
+func SwaggerUI(w io.Writer, page Page) error {
+	return swaggerUI.Execute(w, page)
+}
+
+// ReDoc формирование страницы ReDoc. Скрипт ReDoc загружается по адресу page.Script,
+// по умолчанию из встроенных ресурсов по адресу page.Assets
+func ReDoc(w io.Writer, page Page) error {
+	if page.Script == "" {
+		page.Script = page.Assets + "/" + RedocScript
+	}
+	return redoc.Execute(w, page)
+}
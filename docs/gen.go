@@ -0,0 +1,46 @@
+//go:build ignore
+// +build ignore
+
+// Загрузка зафиксированной версии ReDoc во встроенные ресурсы: go generate ./docs
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// redocVersion версия ReDoc, должна совпадать с docs.RedocVersion
+const redocVersion = "2.1.3"
+
+var files = map[string]string{
+	"redoc.standalone.js": "https://cdn.jsdelivr.net/npm/redoc@" + redocVersion + "/bundles/redoc.standalone.js",
+	"redoc.LICENSE":       "https://cdn.jsdelivr.net/npm/redoc@" + redocVersion + "/LICENSE",
+}
+
+func main() {
+	for name, url := range files {
+		if err := download(filepath.Join("assets", name), url); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func download(name, url string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, b, 0644)
+}
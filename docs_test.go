@@ -0,0 +1,84 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"github.com/egovorukhin/egowebapi/security"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_Docs(t *testing.T) {
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		Authorization: security.Authorization{
+			Basic: &security.Basic{
+				Handler: func(user string, pass string) bool {
+					return user == "admin"
+				},
+			},
+		},
+		Docs: &Docs{
+			Path:     "/docs",
+			Security: []string{security.BasicAuth},
+		},
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(NewContext(c))
+			})
+		},
+	})
+	s.Register(new(Faulty)).SetPath("/faulty")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string, auth bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "http://example.com:8443"+path, nil)
+		r.Header.Set("X-Forwarded-Proto", "https")
+		if auth {
+			r.SetBasicAuth("admin", "")
+		}
+		w := httptest.NewRecorder()
+		web.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("/docs/swagger.json", false); w.Code != 401 {
+		t.Errorf("status %d, want 401", w.Code)
+	}
+
+	w := get("/docs/swagger.json", true)
+	var doc Swagger
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Host != "example.com:8443" || len(doc.Schemes) != 1 || doc.Schemes[0] != "https" {
+		t.Errorf("host %s, schemes %v", doc.Host, doc.Schemes)
+	}
+	if _, ok := doc.Paths["faulty"]; !ok || len(doc.Paths) != 1 {
+		t.Errorf("paths %v, want only controller paths", doc.Paths)
+	}
+	if s.Swagger.Host != "localhost:0" {
+		t.Errorf("server swagger host changed: %s", s.Swagger.Host)
+	}
+
+	if w = get("/docs/swagger.yaml", true); !strings.Contains(w.Body.String(), `swagger: "2.0"`) {
+		t.Errorf("yaml %s", w.Body.String())
+	}
+	if w = get("/docs/swagger", true); !strings.Contains(w.Body.String(), `src="/docs/swagger/swagger-ui-bundle.js"`) {
+		t.Errorf("swagger ui %s", w.Body.String())
+	}
+	if w = get("/docs/redoc", true); !strings.Contains(w.Body.String(), `spec-url="/docs/swagger.json"`) ||
+		!strings.Contains(w.Body.String(), `src="/docs/swagger/redoc.standalone.js"`) {
+		t.Errorf("redoc %s", w.Body.String())
+	}
+	if w = get("/docs/swagger/redoc.standalone.js", true); w.Code != 200 || w.Body.Len() == 0 {
+		t.Errorf("redoc script status %d, size %d", w.Code, w.Body.Len())
+	}
+	if w = get("/docs/swagger/swagger-ui.css", true); w.Code != 200 || w.Body.Len() == 0 {
+		t.Errorf("asset status %d, size %d", w.Code, w.Body.Len())
+	}
+}
@@ -2,7 +2,9 @@ package echo
 
 import (
 	"context"
+	"errors"
 	"github.com/labstack/echo/v4"
+	"net/http"
 )
 
 type Server struct {
@@ -10,15 +12,23 @@ type Server struct {
 }
 
 func (s *Server) Start(addr string) error {
-	return s.App.Start(addr)
+	err := s.App.Start(addr)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
 func (s *Server) StartTLS(addr, cert, key string) error {
-	return s.App.StartTLS(addr, cert, key)
+	err := s.App.StartTLS(addr, cert, key)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (s *Server) Stop() error {
-	return s.App.Shutdown(context.Background())
+func (s *Server) Stop(ctx context.Context) error {
+	return s.App.Shutdown(ctx)
 }
 
 func (s *Server) Static(prefix, root string) {
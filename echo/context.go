@@ -1,6 +1,7 @@
 package echo
 
 import (
+	"bytes"
 	"github.com/labstack/echo/v4"
 	"io"
 	"io/ioutil"
@@ -35,7 +36,7 @@ func (c *Context) Get(key string, defaultValue ...string) string {
 }
 
 func (c *Context) Set(key, value string) {
-	c.Ctx.Request().Header.Set(key, value)
+	c.Ctx.Response().Header().Set(key, value)
 }
 
 func (c *Context) SendStatus(code int) error {
@@ -72,6 +73,18 @@ func (c *Context) Path() string {
 	return c.Ctx.Path()
 }
 
+func (c *Context) Method() string {
+	return c.Ctx.Request().Method
+}
+
+// RequestURI путь запроса вместе с параметрами адресной строки, как его передал клиент
+func (c *Context) RequestURI() string {
+	if c.Ctx.Request().RequestURI != "" {
+		return c.Ctx.Request().RequestURI
+	}
+	return c.Ctx.Request().URL.RequestURI()
+}
+
 func (c *Context) SendString(code int, s string) error {
 	return c.Ctx.String(code, s)
 }
@@ -145,6 +158,8 @@ func (c *Context) Body() []byte {
 	body := c.Ctx.Request().Body
 	b, _ := ioutil.ReadAll(body)
 	defer body.Close()
+	// Возвращаем тело запроса для повторного чтения
+	c.Ctx.Request().Body = ioutil.NopCloser(bytes.NewReader(b))
 	return b
 }
 
@@ -0,0 +1,127 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/egovorukhin/egowebapi/consts"
+	"net/http"
+)
+
+// MIMEApplicationProblemJSON тип содержимого ответа с описанием ошибки (RFC 7807)
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Error ошибка обработчика с кодом ответа HTTP
+type Error struct {
+	Status int
+	Type   string
+	Title  string
+	Detail string
+	Err    error
+}
+
+// NewError Инициализация ошибки с кодом ответа. Детали объединяются как в fmt.Sprint,
+// если передана одна ошибка, то она сохраняется и доступна через errors.Unwrap
+func NewError(status int, detail ...interface{}) *Error {
+	e := &Error{
+		Status: status,
+		Detail: fmt.Sprint(detail...),
+	}
+	if len(detail) == 1 {
+		if err, ok := detail[0].(error); ok {
+			e.Err = err
+		}
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return http.StatusText(e.Status)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Problem описание ошибки в формате RFC 7807
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// DefaultErrorHandler обработчик ошибок по умолчанию, отправляет ответ application/problem+json
+func DefaultErrorHandler(c *Context, statusCode int, err interface{}) error {
+
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Instance: c.Path(),
+	}
+
+	switch e := err.(type) {
+	case error:
+		p.Detail = e.Error()
+		var ewaErr *Error
+		if errors.As(e, &ewaErr) {
+			if ewaErr.Type != "" {
+				p.Type = ewaErr.Type
+			}
+			if ewaErr.Title != "" {
+				p.Title = ewaErr.Title
+			}
+		}
+		var ve ValidationError
+		if errors.As(e, &ve) {
+			p.Errors = ve.Errors
+		}
+	case nil:
+	default:
+		p.Detail = fmt.Sprint(e)
+	}
+
+	b, e := json.Marshal(p)
+	if e != nil {
+		return e
+	}
+	return c.Send(statusCode, MIMEApplicationProblemJSON, b)
+}
+
+// errorHandler обработчик ошибок из конфигурации или по умолчанию
+func (s *Server) errorHandler() ErrorHandler {
+	if s.Config.ErrorHandler != nil {
+		return s.Config.ErrorHandler
+	}
+	return DefaultErrorHandler
+}
+
+// recover передаем ошибки обработчика и панику в ErrorHandler.
+// Код ответа берется из Error, иначе 500
+func (s *Server) recover(next Handler) Handler {
+	return func(c *Context) (err error) {
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = s.errorHandler()(c, consts.StatusInternalServerError, fmt.Errorf("panic: %v", r))
+			}
+		}()
+
+		if err = next(c); err != nil {
+			status := consts.StatusInternalServerError
+			var e *Error
+			if errors.As(err, &e) && e.Status != 0 {
+				status = e.Status
+			}
+			return s.errorHandler()(c, status, err)
+		}
+
+		return nil
+	}
+}
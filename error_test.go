@@ -0,0 +1,73 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"net/http/httptest"
+	"testing"
+)
+
+type Faulty struct{}
+
+func (Faulty) Get(route *Route) {
+	route.Handler = func(c *Context) error {
+		return NewError(404, "user ", 7, " not found")
+	}
+}
+
+func (Faulty) Post(route *Route) {
+	route.Handler = func(c *Context) error {
+		panic("boom")
+	}
+}
+
+func TestServer_ErrorHandler(t *testing.T) {
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(NewContext(c))
+			})
+		},
+	})
+	s.Register(new(Faulty)).SetPath("/faulty")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		want   Problem
+	}{
+		{"GET", Problem{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "user 7 not found", Instance: "/faulty"}},
+		{"POST", Problem{Type: "about:blank", Title: "Internal Server Error", Status: 500, Detail: "panic: boom", Instance: "/faulty"}},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		web.ServeHTTP(w, httptest.NewRequest(test.method, "/faulty", nil))
+		if ct := w.Header().Get("Content-Type"); ct != MIMEApplicationProblemJSON {
+			t.Errorf("%s: content type %s", test.method, ct)
+		}
+		var p Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != test.want.Status || p.Type != test.want.Type || p.Title != test.want.Title ||
+			p.Status != test.want.Status || p.Detail != test.want.Detail || p.Instance != test.want.Instance {
+			t.Errorf("%s: status %d, problem %+v, want %+v", test.method, w.Code, p, test.want)
+		}
+	}
+
+	// Обработчик из конфигурации
+	var code int
+	s.Config.ErrorHandler = func(c *Context, statusCode int, err interface{}) error {
+		code = statusCode
+		return c.SendString(statusCode, "custom")
+	}
+	w := httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("POST", "/faulty", nil))
+	if code != 500 || w.Body.String() != "custom" {
+		t.Errorf("code %d, body %s, want 500 custom", code, w.Body.String())
+	}
+}
@@ -0,0 +1,95 @@
+// Package ewatest позволяет тестировать контроллеры без запуска слушателя.
+// Маршруты сервера формируются на адаптере net/http, запросы выполняются в памяти,
+// при этом авторизация, сессии и права доступа обрабатываются так же, как в рабочем режиме.
+package ewatest
+
+import (
+	ewa "github.com/egovorukhin/egowebapi"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"net/http"
+	"testing"
+)
+
+// Tester тестовый веб сервер
+type Tester struct {
+	tb     testing.TB
+	Server *ewa.Server
+	web    *nethttp.Server
+}
+
+// New Инициализация тестового сервера. Веб сервер заменяется на net/http, ContextHandler
+// по умолчанию - ContextHandler этого пакета. Собственный ContextHandler сервера сохраняется
+// и должен возвращать обработчик адаптера net/http. После этого формируются все маршруты
+// зарегистрированных контроллеров
+func New(tb testing.TB, server *ewa.Server) *Tester {
+	tb.Helper()
+
+	web := &nethttp.Server{}
+	if views := server.Config.Views; views != nil {
+		ext := views.Engine
+		if ext == "" {
+			ext = nethttp.Html
+		}
+		web.Renderer = nethttp.NewViews(views.Root, ext, views.Layout)
+	}
+	if server.Config.Static != nil {
+		web.Static(server.Config.Static.Prefix, server.Config.Static.Root)
+	}
+
+	server.WebServer = web
+	if server.Config.ContextHandler == nil {
+		server.Config.ContextHandler = ContextHandler
+	}
+
+	if err := server.Build(); err != nil {
+		tb.Fatalf("ewatest: %s", err)
+	}
+
+	return &Tester{
+		tb:     tb,
+		Server: server,
+		web:    web,
+	}
+}
+
+// ContextHandler обработчик контекста для адаптера net/http
+func ContextHandler(handler ewa.Handler) interface{} {
+	return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+		return handler(ewa.NewContext(c))
+	})
+}
+
+// Handler вернуть http.Handler со всеми маршрутами сервера
+func (t *Tester) Handler() http.Handler {
+	return t.web
+}
+
+// Request Инициализация запроса
+func (t *Tester) Request(method, path string) *Request {
+	return newRequest(t, method, path)
+}
+
+// Get запрос GET
+func (t *Tester) Get(path string) *Request {
+	return t.Request(http.MethodGet, path)
+}
+
+// Post запрос POST
+func (t *Tester) Post(path string) *Request {
+	return t.Request(http.MethodPost, path)
+}
+
+// Put запрос PUT
+func (t *Tester) Put(path string) *Request {
+	return t.Request(http.MethodPut, path)
+}
+
+// Patch запрос PATCH
+func (t *Tester) Patch(path string) *Request {
+	return t.Request(http.MethodPatch, path)
+}
+
+// Delete запрос DELETE
+func (t *Tester) Delete(path string) *Request {
+	return t.Request(http.MethodDelete, path)
+}
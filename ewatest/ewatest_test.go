@@ -0,0 +1,568 @@
+package ewatest
+
+import (
+	"errors"
+	ewa "github.com/egovorukhin/egowebapi"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"github.com/egovorukhin/egowebapi/security"
+	"github.com/egovorukhin/egowebapi/session"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+type User struct{}
+
+func (User) Get(route *ewa.Route) {
+	route.SetSecurity(security.BasicAuth).
+		SetParameters(ewa.NewPathParam("/{id}")).
+		Permission()
+	route.Handler = func(c *ewa.Context) error {
+		return c.JSON(200, ewa.Map{"id": c.Params("id"), "user": c.Identity.Username})
+	}
+}
+
+type Robot struct{}
+
+func (Robot) Get(route *ewa.Route) {
+	route.SetSecurity(security.ApiKeyAuth).
+		SetParameters(ewa.NewPathParam("/{id}")).
+		Permission()
+	route.Handler = func(c *ewa.Context) error {
+		return c.JSON(200, ewa.Map{"id": c.Params("id"), "user": c.Identity.Username})
+	}
+}
+
+type Pet struct{}
+
+func (Pet) Get(route *ewa.Route) {
+	route.SetScopes("pets:read").
+		SetParameters(ewa.NewPathParam("/{id}"))
+	route.Handler = func(c *ewa.Context) error {
+		return c.JSON(200, ewa.Map{"id": c.Params("id"), "user": c.Identity.Username})
+	}
+}
+
+func (Pet) Delete(route *ewa.Route) {
+	route.SetSecurity(security.OAuth2Auth).
+		SetScopes("pets:write").
+		SetParameters(ewa.NewPathParam("/{id}"))
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendStatus(204)
+	}
+}
+
+type Admin struct{}
+
+func (Admin) Get(route *ewa.Route) {
+	route.SetSecurity(security.BasicAuth).
+		RequireScopes("admin:read")
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendString(200, "admin")
+	}
+}
+
+func (Admin) Post(route *ewa.Route) {
+	route.SetSecurity(security.ApiKeyAuth)
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendStatus(204)
+	}
+}
+
+// Put роль маршрута дополняет роль контроллера, а не заменяет ее
+func (Admin) Put(route *ewa.Route) {
+	route.SetSecurity(security.BasicAuth, security.ApiKeyAuth).
+		RequireRoles("robot")
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendStatus(204)
+	}
+}
+
+type Mixed struct{}
+
+func (Mixed) Get(route *ewa.Route) {
+	route.SetSecurity(security.BasicAuth, security.ApiKeyAuth)
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendString(200, c.Identity.AuthName)
+	}
+}
+
+func (Mixed) Post(route *ewa.Route) {
+	route.SetSecurityAll(security.BasicAuth, security.ApiKeyAuth)
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendString(200, c.Identity.AuthName)
+	}
+}
+
+func (Mixed) Put(route *ewa.Route) {
+	route.SetSecurity(security.OAuth2Auth, security.BasicAuth)
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendStatus(204)
+	}
+}
+
+func (Mixed) Delete(route *ewa.Route) {
+	route.SetSecurity(security.DigestAuth, security.BasicAuth)
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendStatus(204)
+	}
+}
+
+type Home struct{}
+
+func (Home) Get(route *ewa.Route) {
+	route.Session()
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendString(200, "Hello, "+c.Identity.Username)
+	}
+}
+
+func newServer() *ewa.Server {
+	ws := ewa.New(&nethttp.Server{}, ewa.Config{
+		Authorization: security.Authorization{
+			Identify: func(identity *security.Identity) error {
+				switch identity.Username {
+				case "user":
+					identity.Roles = []string{"admin", "robot"}
+					identity.Scopes = []string{"admin:read"}
+				case "robot":
+					identity.Roles = []string{"robot"}
+				}
+				return nil
+			},
+			Basic: &security.Basic{
+				Handler: func(user string, pass string) bool {
+					return user == "user" && pass == "pass"
+				},
+			},
+			Digest: &security.Digest{
+				Store: security.NewMemoryNonceStore(0),
+				Handler: func(user string, advanced security.Advanced) (string, bool) {
+					return "", false
+				},
+			},
+			OAuth2: &security.OAuth2{
+				Flow:     security.FlowApplication,
+				TokenURL: "/oauth/token",
+				Scopes: map[string]string{
+					"pets:read":  "Read pets",
+					"pets:write": "Modify pets",
+				},
+				Validator: security.TokenValidatorFunc(func(token string) (*security.Token, error) {
+					if token == "down" {
+						return nil, security.ErrValidatorUnavailable
+					}
+					if token != "reader" {
+						return nil, security.ErrTokenInactive
+					}
+					return &security.Token{Subject: "reader", Scopes: []string{"pets:read"}}, nil
+				}),
+			},
+			ApiKey: &security.ApiKey{
+				KeyName: "X-Token",
+				Param:   security.ParamHeader,
+				Handler: func(token string) (string, error) {
+					if token != "token" {
+						return "", errors.New("invalid token")
+					}
+					return "robot", nil
+				},
+			},
+		},
+		Session: &session.Config{
+			SessionHandler: func(value string) (string, error) {
+				if value != "secret" {
+					return "", errors.New("session not found")
+				}
+				return "user", nil
+			},
+		},
+		Permission: &ewa.Permission{
+			Handler: func(username string, path string) bool {
+				return username == "user" || path == "/api/robot/2"
+			},
+		},
+	})
+	ws.Register(new(User)).SetPath("/api/user")
+	ws.Register(new(Robot)).SetPath("/api/robot")
+	ws.Register(new(Pet)).SetPath("/api/pet")
+	ws.Register(new(Admin)).SetPath("/api/admin").RequireRoles("admin")
+	ws.Register(new(Mixed)).SetPath("/api/mixed")
+	ws.Register(new(Home)).SetPath("/")
+	return ws
+}
+
+func TestTester(t *testing.T) {
+
+	tester := New(t, newServer())
+
+	tester.Get("/api/user/1").Expect().
+		Status(401).
+		HeaderExists("WWW-Authenticate")
+
+	tester.Get("/api/user/1").BasicAuth("user", "pass").Expect().
+		Status(200).
+		ContentType("application/json").
+		JSONEq(`{"id":"1","user":"user"}`)
+
+	tester.Get("/api/robot/1").ApiKey("wrong").Expect().
+		Status(401)
+
+	tester.Get("/api/robot/1").ApiKey("token").Expect().
+		Status(403)
+
+	tester.Get("/api/robot/2").ApiKey("token").Expect().
+		Status(200).
+		JSONEq(`{"id":"2","user":"robot"}`)
+
+	tester.Get("/api/pet/1").Expect().
+		Status(401).
+		Header("WWW-Authenticate", "Bearer")
+
+	tester.Get("/api/pet/1").BearerToken("unknown").Expect().
+		Status(401).
+		Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="token is not active"`)
+
+	tester.Get("/api/pet/1").BearerToken("down").Expect().
+		Status(503)
+
+	tester.Get("/api/pet/1").BearerToken("reader").Expect().
+		Status(200).
+		JSONEq(`{"id":"1","user":"reader"}`)
+
+	tester.Delete("/api/pet/1").BearerToken("reader").Expect().
+		Status(403).
+		Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="pets:write"`)
+
+	op := tester.Server.Swagger.Paths["api/pet/{id}"]["delete"]
+	if scopes := op.Security[0][security.OAuth2Auth]; len(scopes) != 1 || scopes[0] != "pets:write" {
+		t.Errorf("swagger security %v", op.Security)
+	}
+
+	tester.Get("/api/admin").BasicAuth("user", "pass").Expect().
+		Status(200).
+		Body("admin")
+
+	tester.Post("/api/admin").ApiKey("token").Expect().
+		Status(403)
+
+	tester.Put("/api/admin").ApiKey("token").Expect().
+		Status(403)
+
+	tester.Put("/api/admin").BasicAuth("user", "pass").Expect().
+		Status(204)
+
+	op = tester.Server.Swagger.Paths["api/admin"]["get"]
+	if len(op.ControllerRoles) != 1 || op.ControllerRoles[0] != "admin" || len(op.RequiredScopes) != 1 || op.Responses["403"] == nil {
+		t.Errorf("swagger requirements %v %v %v", op.ControllerRoles, op.RequiredScopes, op.Responses)
+	}
+
+	// Альтернативы: достаточно одного метода, вызов Basic не отправляется
+	tester.Get("/api/mixed").ApiKey("token").Expect().
+		Status(200).
+		Body("ApiKey").
+		Header("WWW-Authenticate", "")
+
+	// Сочетание: необходимы оба метода
+	tester.Post("/api/mixed").ApiKey("token").Expect().
+		Status(401).
+		Header("WWW-Authenticate", `Basic realm="Необходимо указать имя пользователя и пароль"`)
+
+	tester.Post("/api/mixed").ApiKey("token").BasicAuth("user", "pass").Expect().
+		Status(200).
+		Body("ApiKey")
+
+	// Вызовы всех не пройденных методов в порядке объявления альтернатив
+	tester.Put("/api/mixed").Expect().
+		Status(401).
+		Header("WWW-Authenticate", `Bearer, Basic realm="Необходимо указать имя пользователя и пароль"`)
+
+	// Nonce выдается, только когда вызов Digest отправляется клиенту
+	nonces := tester.Server.Config.Authorization.Digest.Store.(*security.MemoryNonceStore)
+	tester.Delete("/api/mixed").BasicAuth("user", "pass").Expect().
+		Status(204)
+	if n := nonces.Len(); n != 0 {
+		t.Errorf("nonces after basic %d", n)
+	}
+	tester.Delete("/api/mixed").Expect().
+		Status(401)
+	if n := nonces.Len(); n != 1 {
+		t.Errorf("nonces after challenge %d", n)
+	}
+
+	op = tester.Server.Swagger.Paths["api/mixed"]["post"]
+	if len(op.Security) != 1 || len(op.Security[0]) != 2 {
+		t.Errorf("swagger security %v", op.Security)
+	}
+
+	tester.Get("/").Expect().
+		Redirect("/login")
+
+	tester.Get("/").Session("secret").Expect().
+		Status(200).
+		Body("Hello, user")
+}
+
+type Login struct{}
+
+func (Login) Get(route *ewa.Route) {
+	route.Handler = func(c *ewa.Context) error {
+		return c.Render("login", ewa.Map{"Title": "Sign in"})
+	}
+}
+
+func (Login) Post(route *ewa.Route) {
+	route.Session(ewa.On)
+	route.Handler = func(c *ewa.Context) error {
+		user := c.QueryParam("user")
+		if user == "" {
+			c.Session.AddFlash("error", "user is required")
+			return c.Redirect("/login", 303)
+		}
+		c.Session.User = user
+		return c.SendStatus(204)
+	}
+}
+
+type Counter struct{}
+
+func (Counter) Get(route *ewa.Route) {
+	route.Session()
+	route.Handler = func(c *ewa.Context) error {
+		visits := 0
+		if err := c.Session.Get("visits", &visits); err != nil && err != ewa.ErrSessionKeyNotFound {
+			return err
+		}
+		visits++
+		if err := c.Session.Set("visits", visits); err != nil {
+			return err
+		}
+		return c.SendString(200, strconv.Itoa(visits))
+	}
+}
+
+type Anonymous struct{}
+
+func (Anonymous) Post(route *ewa.Route) {
+	route.Session(ewa.On)
+	route.Handler = func(c *ewa.Context) error {
+		return c.SendStatus(204)
+	}
+}
+
+type Whoami struct{}
+
+func (Whoami) Get(route *ewa.Route) {
+	route.Handler = func(c *ewa.Context) error {
+		// Сессия маршрута без проверки загружается только по требованию
+		if c.Session != nil {
+			return c.SendStatus(500)
+		}
+		if s := c.LoadSession(); s != nil {
+			return c.SendString(200, s.User)
+		}
+		return c.SendStatus(204)
+	}
+}
+
+type Logout struct{}
+
+func (Logout) Get(route *ewa.Route) {
+	route.Session(ewa.Off)
+	route.Handler = func(c *ewa.Context) error {
+		return nil
+	}
+}
+
+func TestTester_AllRoutes(t *testing.T) {
+
+	ws := newServer()
+	ws.Config.Authorization.AllRoutes = security.ApiKeyAuth
+	tester := New(t, ws)
+
+	// Метод по умолчанию не заменяет обязательную комбинацию методов
+	tester.Post("/api/mixed").ApiKey("token").Expect().
+		Status(401)
+	tester.Post("/api/mixed").BasicAuth("user", "pass").Expect().
+		Status(401)
+	tester.Post("/api/mixed").ApiKey("token").BasicAuth("user", "pass").Expect().
+		Status(200)
+
+	// И обязательные области доступа
+	tester.Get("/api/pet/1").ApiKey("token").Expect().
+		Status(401)
+	tester.Get("/api/pet/1").BearerToken("reader").Expect().
+		Status(200)
+
+	// Явно указанные альтернативы дополняют метод по умолчанию
+	tester.Get("/api/mixed").ApiKey("token").Expect().
+		Status(200)
+	if op := tester.Server.Swagger.Paths["api/mixed"]["post"]; len(op.Security) != 1 {
+		t.Errorf("swagger security %v", op.Security)
+	}
+}
+
+func TestTester_ContextHandler(t *testing.T) {
+
+	ws := newServer()
+	// Собственный обработчик контекста не заменяется
+	ws.Config.ContextHandler = func(handler ewa.Handler) interface{} {
+		return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+			c.Set("X-Context", "custom")
+			return handler(ewa.NewContext(c))
+		})
+	}
+	tester := New(t, ws)
+
+	tester.Get("/").Session("secret").Expect().
+		Status(200).
+		Header("X-Context", "custom")
+}
+
+func TestTester_Session(t *testing.T) {
+
+	views := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(views, "login.html"), []byte(`{{range .Flashes}}{{.Type}}: {{.Message}}; {{end}}{{.Title}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws := ewa.New(&nethttp.Server{}, ewa.Config{
+		Views:   &ewa.Views{Root: views},
+		Session: &session.Config{},
+	})
+	ws.Register(new(Login)).SetPath("/login")
+	ws.Register(new(Logout)).SetPath("/logout")
+	ws.Register(new(Counter)).SetPath("/counter")
+	ws.Register(new(Whoami)).SetPath("/whoami")
+	ws.Register(new(Anonymous)).SetPath("/anonymous")
+	ws.Register(new(Home)).SetPath("/")
+	tester := New(t, ws)
+
+	tester.Get("/whoami").Expect().
+		Status(204)
+
+	// Пустая сессия не сохраняется, и cookie для нее не отправляется
+	if cookie := tester.Post("/anonymous").Expect().Status(204).Cookie("session_id"); cookie != nil {
+		t.Errorf("empty session cookie %v", cookie)
+	}
+
+	// Сообщение показывается один раз после перенаправления
+	cookie := tester.Post("/login").Expect().
+		Redirect("/login").
+		Cookie("session_id")
+	if cookie == nil {
+		t.Fatal("session cookie is not set")
+	}
+	// Сессия без пользователя не проходит проверку
+	tester.Get("/").Session(cookie.Value).Expect().
+		Redirect("/login")
+	tester.Get("/login").Session(cookie.Value).Expect().
+		Status(200).
+		Body("error: user is required; Sign in")
+	tester.Get("/login").Session(cookie.Value).Expect().
+		Body("Sign in")
+
+	cookie = tester.Post("/login").Query("user", "alice").Expect().
+		Status(204).
+		Cookie("session_id")
+
+	tester.Get("/").Session(cookie.Value).Expect().
+		Status(200).
+		Body("Hello, alice")
+	tester.Get("/whoami").Session(cookie.Value).Expect().
+		Body("alice")
+
+	tester.Get("/counter").Session(cookie.Value).Expect().
+		Body("1")
+	tester.Get("/counter").Session(cookie.Value).Expect().
+		Body("2")
+
+	tester.Get("/logout").Session(cookie.Value).Expect().
+		Redirect("/login")
+
+	tester.Get("/").Session(cookie.Value).Expect().
+		Redirect("/login")
+}
+
+func TestTester_CookieSession(t *testing.T) {
+
+	views := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(views, "login.html"), []byte(`{{range .Flashes}}{{.Type}}: {{.Message}}; {{end}}{{.Title}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws := ewa.New(&nethttp.Server{}, ewa.Config{
+		Views: &ewa.Views{Root: views},
+		Session: &session.Config{
+			Cookie: &session.Cookie{
+				Keys:     [][]byte{[]byte("0123456789abcdef0123456789abcdef")},
+				Encrypt:  true,
+				Secure:   true,
+				SameSite: http.SameSiteStrictMode,
+			},
+		},
+	})
+	ws.Register(new(Login)).SetPath("/login")
+	ws.Register(new(Logout)).SetPath("/logout")
+	ws.Register(new(Counter)).SetPath("/counter")
+	ws.Register(new(Anonymous)).SetPath("/anonymous")
+	ws.Register(new(Home)).SetPath("/")
+	tester := New(t, ws)
+
+	// Новая сессия без пользователя не записывается
+	if tester.Post("/anonymous").Expect().Cookie("session_id") != nil {
+		t.Error("anonymous session cookie is set")
+	}
+
+	// Cookie записывается до перенаправления, сообщение удаляется после показа
+	cookie := tester.Post("/login").Expect().
+		Redirect("/login").
+		Cookie("session_id")
+	if cookie == nil {
+		t.Fatal("session cookie is not set")
+	}
+	if !cookie.Secure || !cookie.HttpOnly || cookie.SameSite != http.SameSiteStrictMode {
+		t.Errorf("session cookie attributes: %v", cookie)
+	}
+	// Сессия без пользователя не проходит проверку
+	tester.Get("/").Session(cookie.Value).Expect().
+		Redirect("/login")
+	resp := tester.Get("/login").Session(cookie.Value).Expect().
+		Body("error: user is required; Sign in")
+	if c := resp.Cookie("session_id"); c == nil || c.Value == cookie.Value {
+		t.Fatal("session cookie is not updated after flashes")
+	}
+
+	cookie = tester.Post("/login").Query("user", "alice").Expect().
+		Status(204).
+		Cookie("session_id")
+
+	// Без изменений cookie не перезаписывается
+	resp = tester.Get("/").Session(cookie.Value).Expect().
+		Body("Hello, alice")
+	if resp.Cookie("session_id") != nil {
+		t.Error("unchanged session cookie is rewritten")
+	}
+
+	cookie = tester.Get("/counter").Session(cookie.Value).Expect().
+		Body("1").
+		Cookie("session_id")
+	tester.Get("/counter").Session(cookie.Value).Expect().
+		Body("2")
+
+	// Подделанное значение не принимается
+	tampered := []byte(cookie.Value)
+	tampered[20] ^= 1
+	tester.Get("/").Session(string(tampered)).Expect().
+		Redirect("/login")
+
+	if c := tester.Get("/logout").Session(cookie.Value).Expect().
+		Redirect("/login").
+		Cookie("session_id"); c == nil || c.MaxAge >= 0 || !c.Secure {
+		t.Error("session cookie is not cleared")
+	}
+}
@@ -0,0 +1,142 @@
+package ewatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/consts"
+	"github.com/egovorukhin/egowebapi/security"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// Request тестовый запрос
+type Request struct {
+	t       *Tester
+	method  string
+	path    string
+	query   url.Values
+	header  http.Header
+	cookies []*http.Cookie
+	body    io.Reader
+}
+
+func newRequest(t *Tester, method, path string) *Request {
+	return &Request{
+		t:      t,
+		method: method,
+		path:   path,
+		query:  url.Values{},
+		header: http.Header{},
+	}
+}
+
+// Header Установить заголовок запроса
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query Добавить параметр адресной строки
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Cookie Добавить cookie к запросу
+func (r *Request) Cookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// Body Установить тело запроса
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.header.Set(consts.HeaderContentType, contentType)
+	r.body = bytes.NewReader(body)
+	return r
+}
+
+// JSON Установить тело запроса в формате json
+func (r *Request) JSON(v interface{}) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.t.tb.Fatalf("ewatest: %s", err)
+	}
+	return r.Body(consts.MIMEApplicationJSON, b)
+}
+
+// Form Установить тело запроса в формате application/x-www-form-urlencoded
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body(consts.MIMEApplicationForm, []byte(values.Encode()))
+}
+
+// BasicAuth Установить заголовок Basic авторизации
+func (r *Request) BasicAuth(username, password string) *Request {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, password)
+	return r.Header(consts.HeaderAuthorization, req.Header.Get(consts.HeaderAuthorization))
+}
+
+// BearerToken Установить заголовок авторизации с токеном Bearer
+func (r *Request) BearerToken(token string) *Request {
+	return r.Header(consts.HeaderAuthorization, "Bearer "+token)
+}
+
+// ApiKey Установить ключ авторизации ApiKey в заголовок или в адресную строку
+// в соответствии с настройками сервера
+func (r *Request) ApiKey(token string) *Request {
+	a := r.t.Server.Config.Authorization.ApiKey
+	if a == nil {
+		r.t.tb.Fatalf("ewatest: ApiKey авторизация не настроена")
+		return r
+	}
+	if a.Param == security.ParamQuery {
+		return r.Query(a.KeyName, token)
+	}
+	return r.Header(a.KeyName, token)
+}
+
+// Session Установить cookie сессии
+func (r *Request) Session(value string) *Request {
+	s := r.t.Server.Config.Session
+	if s == nil {
+		r.t.tb.Fatalf("ewatest: сессия не настроена")
+		return r
+	}
+	return r.Cookie(&http.Cookie{
+		Name:  s.KeyName,
+		Value: value,
+	})
+}
+
+// Expect Выполнить запрос и вернуть ответ для проверки
+func (r *Request) Expect() *Response {
+	r.t.tb.Helper()
+
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+
+	req := httptest.NewRequest(r.method, target, r.body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	r.t.web.ServeHTTP(w, req)
+
+	return &Response{
+		tb:       r.t.tb,
+		Recorder: w,
+	}
+}
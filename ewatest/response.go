@@ -0,0 +1,140 @@
+package ewatest
+
+import (
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/consts"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Response ответ тестового запроса
+type Response struct {
+	tb       testing.TB
+	Recorder *httptest.ResponseRecorder
+}
+
+// Status Проверка кода ответа
+func (r *Response) Status(code int) *Response {
+	r.tb.Helper()
+	if r.Recorder.Code != code {
+		r.tb.Errorf("ewatest: status %d, want %d; body: %s", r.Recorder.Code, code, r.String())
+	}
+	return r
+}
+
+// Header Проверка значения заголовка ответа
+func (r *Response) Header(key, value string) *Response {
+	r.tb.Helper()
+	if v := r.Recorder.Header().Get(key); v != value {
+		r.tb.Errorf("ewatest: header %s = %q, want %q", key, v, value)
+	}
+	return r
+}
+
+// HeaderExists Проверка наличия заголовка ответа
+func (r *Response) HeaderExists(key string) *Response {
+	r.tb.Helper()
+	if _, ok := r.Recorder.Header()[http.CanonicalHeaderKey(key)]; !ok {
+		r.tb.Errorf("ewatest: header %s not found", key)
+	}
+	return r
+}
+
+// ContentType Проверка типа содержимого ответа
+func (r *Response) ContentType(contentType string) *Response {
+	r.tb.Helper()
+	if v := r.Recorder.Header().Get(consts.HeaderContentType); !strings.HasPrefix(v, contentType) {
+		r.tb.Errorf("ewatest: content type %q, want %q", v, contentType)
+	}
+	return r
+}
+
+// Body Проверка тела ответа на равенство
+func (r *Response) Body(body string) *Response {
+	r.tb.Helper()
+	if s := r.String(); s != body {
+		r.tb.Errorf("ewatest: body %q, want %q", s, body)
+	}
+	return r
+}
+
+// BodyContains Проверка вхождения строки в тело ответа
+func (r *Response) BodyContains(substr string) *Response {
+	r.tb.Helper()
+	if s := r.String(); !strings.Contains(s, substr) {
+		r.tb.Errorf("ewatest: body %q does not contain %q", s, substr)
+	}
+	return r
+}
+
+// JSONEq Проверка тела ответа на равенство json без учета форматирования и порядка ключей
+func (r *Response) JSONEq(expected string) *Response {
+	r.tb.Helper()
+	var want, got interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		r.tb.Fatalf("ewatest: expected json: %s", err)
+	}
+	if err := json.Unmarshal(r.Bytes(), &got); err != nil {
+		r.tb.Errorf("ewatest: response json: %s; body: %s", err, r.String())
+		return r
+	}
+	if !reflect.DeepEqual(want, got) {
+		r.tb.Errorf("ewatest: json %s, want %s", r.String(), expected)
+	}
+	return r
+}
+
+// Redirect Проверка перенаправления
+func (r *Response) Redirect(location string) *Response {
+	r.tb.Helper()
+	if r.Recorder.Code < 300 || r.Recorder.Code >= 400 {
+		r.tb.Errorf("ewatest: status %d is not redirect", r.Recorder.Code)
+	}
+	return r.Header(consts.HeaderLocation, location)
+}
+
+// CookieExists Проверка установки cookie в ответе
+func (r *Response) CookieExists(name string) *Response {
+	r.tb.Helper()
+	if r.Cookie(name) == nil {
+		r.tb.Errorf("ewatest: cookie %s not found", name)
+	}
+	return r
+}
+
+// Decode Разобрать тело ответа в формате json
+func (r *Response) Decode(out interface{}) *Response {
+	r.tb.Helper()
+	if err := json.Unmarshal(r.Bytes(), out); err != nil {
+		r.tb.Errorf("ewatest: decode json: %s; body: %s", err, r.String())
+	}
+	return r
+}
+
+// Cookie Вернуть cookie, установленную в ответе
+func (r *Response) Cookie(name string) *http.Cookie {
+	for _, cookie := range r.Recorder.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// Bytes Вернуть тело ответа
+func (r *Response) Bytes() []byte {
+	return r.Recorder.Body.Bytes()
+}
+
+// String Вернуть тело ответа строкой
+func (r *Response) String() string {
+	return r.Recorder.Body.String()
+}
+
+// Result Вернуть ответ
+func (r *Response) Result() *http.Response {
+	return r.Recorder.Result()
+}
@@ -10,9 +10,6 @@ import (
 	"github.com/egovorukhin/egowebapi/security"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"os"
-	"os/signal"
-	"syscall"
 )
 
 func main() {
@@ -84,21 +81,10 @@ func main() {
 		models.ModelResponse: models.Response{},
 	})
 
-	// Канал для получения ошибки, если таковая будет
-	errChan := make(chan error, 2)
-	go func() {
-		errChan <- ws.Start()
-	}()
-
-	// Ждем сигнал от ОС
-	go getSignal(errChan)
-
 	fmt.Println("Старт приложения")
-	fmt.Printf("Остановка приложения. %s", <-errChan)
-}
-
-func getSignal(errChan chan error) {
-	c := make(chan os.Signal)
-	signal.Notify(c, syscall.SIGINT)
-	errChan <- fmt.Errorf("%s", <-c)
+	// Запуск и ожидание сигнала от ОС для плавной остановки
+	if err := ws.Run(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("Остановка приложения")
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	ewa "github.com/egovorukhin/egowebapi"
+	"github.com/egovorukhin/egowebapi/example/gin-api/controllers"
+	"github.com/egovorukhin/egowebapi/example/gin-api/controllers/api/storage"
+	"github.com/egovorukhin/egowebapi/example/gin-api/models"
+	g "github.com/egovorukhin/egowebapi/gin"
+	"github.com/egovorukhin/egowebapi/security"
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+
+	//BasicAuth
+	basicAuthHandler := func(user string, pass string) bool {
+		if user == "user" && pass == "Qq123456" {
+			return true
+		}
+		return false
+	}
+
+	contextHandler := func(handler ewa.Handler) interface{} {
+		return func(ctx *gin.Context) {
+			if err := handler(ewa.NewContext(&g.Context{Ctx: ctx})); err != nil {
+				ctx.String(500, err.Error())
+			}
+		}
+	}
+
+	// Gin
+	app := gin.New()
+	app.Use(gin.Logger(), gin.Recovery())
+	server := &g.Server{App: app}
+	// Конфиг
+	cfg := ewa.Config{
+		Port: 8070,
+		Authorization: security.Authorization{
+			Basic: &security.Basic{
+				Handler: basicAuthHandler,
+			},
+		},
+		// Документация: /docs/swagger.json, /docs/swagger.yaml, /docs/swagger, /docs/redoc
+		Docs: &ewa.Docs{
+			Path:     "/docs",
+			Security: []string{security.BasicAuth},
+		},
+		ContextHandler: contextHandler,
+	}
+
+	info := ewa.Info{
+		Description: "Description",
+		Version:     "1.0.0",
+		Title:       "GinApi",
+		Contact: &ewa.Contact{
+			Email: "user@mail.ru",
+		},
+		License: &ewa.License{
+			Name: "License",
+		},
+	}
+
+	hostname := ewa.Suffix{
+		Index:       3,
+		Value:       "{hostname}",
+		Description: "Set hostname device",
+	}
+
+	//Инициализируем сервер
+	ws := ewa.New(server, cfg)
+	ws.Register(new(storage.User)).SetSuffix(hostname).SetDescription("Users")
+	ws.Register(new(controllers.Home)).SetPath("/")
+
+	// Описываем swagger
+	ws.Swagger.SetInfo("localhost", &info, nil).SetBasePath("/api")
+	ws.Swagger.SetModels(ewa.Models{
+		models.ModelUser:     models.User{},
+		models.ModelResponse: models.Response{},
+	})
+
+	fmt.Println("Старт приложения")
+	// Запуск и ожидание сигнала от ОС для плавной остановки
+	if err := ws.Run(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println("Остановка приложения")
+}
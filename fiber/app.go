@@ -1,6 +1,7 @@
 package fiber
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -17,8 +18,18 @@ func (s *Server) StartTLS(addr, cert, key string) (err error) {
 	return s.App.ListenTLS(addr, cert, key)
 }
 
-func (s *Server) Stop() error {
-	return s.App.Shutdown()
+func (s *Server) Stop(ctx context.Context) error {
+	// fiber не принимает контекст, поэтому ожидаем остановку не дольше срока контекста
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.App.Shutdown()
+	}()
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (s *Server) Static(prefix, root string) {
@@ -32,7 +43,7 @@ func (s *Server) Any(path string, handler interface{}) {
 }
 
 func (s *Server) Use(params ...interface{}) {
-	s.App.Use(params)
+	s.App.Use(params...)
 }
 
 func (s *Server) Add(method, path string, handler interface{}) {
@@ -64,6 +64,15 @@ func (c *Context) Path() string {
 	return c.Ctx.Path()
 }
 
+func (c *Context) Method() string {
+	return c.Ctx.Method()
+}
+
+// RequestURI путь запроса вместе с параметрами адресной строки, как его передал клиент
+func (c *Context) RequestURI() string {
+	return c.Ctx.OriginalURL()
+}
+
 func (c *Context) SendString(code int, s string) error {
 	return c.Ctx.Status(code).SendString(s)
 }
@@ -1,24 +1,53 @@
 package gin
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
 )
 
 type Server struct {
 	App *gin.Engine
+	// Renderer шаблонизатор с поддержкой макетов, например nethttp.NewViews.
+	// Если не указан, то используются шаблоны gin без макетов
+	Renderer Renderer
+	server   *http.Server
 }
 
+// Renderer интерфейс шаблонизатора
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}, layouts ...string) error
+}
+
+// rendererKey ключ шаблонизатора в контексте gin
+const rendererKey = "ewa.renderer"
+
 func (s *Server) Start(addr string) error {
-	return s.App.Run(addr)
+	s.server = &http.Server{Addr: addr, Handler: s.App}
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
 func (s *Server) StartTLS(addr, cert, key string) error {
-	return s.App.RunTLS(addr, cert, key)
+	s.server = &http.Server{Addr: addr, Handler: s.App}
+	err := s.server.ListenAndServeTLS(cert, key)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (s *Server) Stop() error {
-	return nil
-	//return s.App.Shutdown(context.Background())
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
 }
 
 func (s *Server) Static(prefix, root string) {
@@ -26,21 +55,19 @@ func (s *Server) Static(prefix, root string) {
 }
 
 func (s *Server) Any(path string, handler interface{}) {
-	if h, ok := handler.(gin.HandlerFunc); ok {
-		s.App.Any(path, h)
-	}
+	s.App.Any(path, s.handler(handler))
 }
 
 func (s *Server) Use(params ...interface{}) {
 	for _, param := range params {
-		if h, ok := param.(gin.HandlerFunc); ok {
+		if h, ok := toHandler(param); ok {
 			s.App.Use(h)
 		}
 	}
 }
 
 func (s *Server) Add(method, path string, handler interface{}) {
-	s.App.Handle(method, path, handler.(gin.HandlerFunc))
+	s.App.Handle(method, path, s.handler(handler))
 }
 
 func (s *Server) GetApp() interface{} {
@@ -48,11 +75,38 @@ func (s *Server) GetApp() interface{} {
 }
 
 func (s *Server) NotFoundPage(path, page string) {
-	s.App.Any(path, func(c *gin.Context) {
-		c.HTML(200, page, nil)
+	s.App.NoRoute(func(c *gin.Context) {
+		c.HTML(http.StatusNotFound, page, nil)
 	})
 }
 
 func (s *Server) ConvertParam(param string) string {
-	return "{" + param + "}"
+	return ":" + param
+}
+
+// handler обработчик маршрута с шаблонизатором сервера в контексте.
+// Обработчик неподдерживаемого типа вызывает panic, как и в других адаптерах
+func (s *Server) handler(handler interface{}) gin.HandlerFunc {
+	h, ok := toHandler(handler)
+	if !ok {
+		panic(fmt.Sprintf("gin: unsupported handler type %T", handler))
+	}
+	if s.Renderer == nil {
+		return h
+	}
+	return func(c *gin.Context) {
+		c.Set(rendererKey, s.Renderer)
+		h(c)
+	}
+}
+
+// toHandler приведение обработчика к gin.HandlerFunc
+func toHandler(handler interface{}) (gin.HandlerFunc, bool) {
+	switch h := handler.(type) {
+	case gin.HandlerFunc:
+		return h, true
+	case func(*gin.Context):
+		return h, true
+	}
+	return nil, false
 }
@@ -0,0 +1,54 @@
+package gin
+
+import (
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestServer_Render(t *testing.T) {
+
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	files := map[string]string{
+		"layout.html": `<main>{{template "content" .}}</main>`,
+		"page.html":   `{{define "content"}}Hi {{.}}{{end}}`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var err error
+	page := func(ctx *gin.Context) {
+		err = (&Context{Ctx: ctx}).Render("page", "ewa", "layout")
+	}
+
+	server := &Server{App: gin.New(), Renderer: nethttp.NewViews(root, nethttp.Html)}
+	server.Add(http.MethodGet, "/page", page)
+	w := httptest.NewRecorder()
+	server.App.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+	if err != nil || w.Body.String() != "<main>Hi ewa</main>" {
+		t.Errorf("body %q, error %v", w.Body.String(), err)
+	}
+
+	// Шаблоны gin не поддерживают макеты
+	server = &Server{App: gin.New()}
+	server.Add(http.MethodGet, "/page", page)
+	server.App.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/page", nil))
+	if err == nil {
+		t.Error("want error: layout without Renderer")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic: unsupported handler type")
+		}
+	}()
+	server.Add(http.MethodGet, "/nil", func() {})
+}
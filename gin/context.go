@@ -1,10 +1,45 @@
 package gin
 
-/*type Context struct {
-	Ctx *gin.Context
-}
-
-func (c *Context) Render(name string, data interface{}, layouts ...string) error {
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type Context struct {
+	Ctx  *gin.Context
+	body []byte
+}
+
+// Render отрисовка шаблона. С шаблонизатором сервера поддерживаются макеты layouts,
+// шаблоны gin макеты не поддерживают, поэтому без шаблонизатора макет приводит к ошибке.
+// gin вызывает panic при ошибке шаблона, поэтому перехватываем ее и возвращаем как ошибку
+func (c *Context) Render(name string, data interface{}, layouts ...string) (err error) {
+	if value, ok := c.Ctx.Get(rendererKey); ok {
+		buf := &bytes.Buffer{}
+		if err = value.(Renderer).Render(buf, name, data, layouts...); err != nil {
+			return err
+		}
+		c.Ctx.Data(200, "text/html; charset=utf-8", buf.Bytes())
+		return nil
+	}
+	if len(layouts) > 0 && layouts[0] != "" {
+		return errors.New("gin: layouts require Server.Renderer")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
 	c.Ctx.HTML(200, name, data)
 	return nil
 }
@@ -31,6 +66,7 @@ func (c *Context) Set(key, value string) {
 
 func (c *Context) SendStatus(code int) error {
 	c.Ctx.Status(code)
+	c.Ctx.Writer.WriteHeaderNow()
 	return nil
 }
 
@@ -39,17 +75,18 @@ func (c *Context) Cookies(key string) string {
 	return value
 }
 
-func (c *Context) SetCookie(cookie *consts.Cookie) {
-	c.Ctx.SetCookie(cookie.Name, cookie.Value, cookie.MaxAge, cookie.Path, cookie.Domain, cookie.Secure, cookie.HttpOnly)
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.Ctx.Writer, cookie)
 }
 
-// TODO ClearCookie
 func (c *Context) ClearCookie(key string) {
-	for _, cookie := range c.Ctx..Cookies() {
-		if cookie.Name == key {
-
-		}
-	}
+	http.SetCookie(c.Ctx.Writer, &http.Cookie{
+		Name:    key,
+		Value:   "",
+		Path:    "/",
+		MaxAge:  -1,
+		Expires: time.Unix(0, 0),
+	})
 }
 
 func (c *Context) Redirect(location string, status int) error {
@@ -58,11 +95,23 @@ func (c *Context) Redirect(location string, status int) error {
 }
 
 func (c *Context) Path() string {
-	return c.Ctx.FullPath()
+	return c.Ctx.Request.URL.Path
+}
+
+func (c *Context) Method() string {
+	return c.Ctx.Request.Method
+}
+
+// RequestURI путь запроса вместе с параметрами адресной строки, как его передал клиент
+func (c *Context) RequestURI() string {
+	if c.Ctx.Request.RequestURI != "" {
+		return c.Ctx.Request.RequestURI
+	}
+	return c.Ctx.Request.URL.RequestURI()
 }
 
 func (c *Context) SendString(code int, s string) error {
-	c.Ctx.Data(code, "", s)
+	c.Ctx.Data(code, "text/plain; charset=utf-8", []byte(s))
 	return nil
 }
 
@@ -72,32 +121,43 @@ func (c *Context) Send(code int, contentType string, b []byte) error {
 }
 
 func (c *Context) SendFile(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
 	c.Ctx.File(file)
 	return nil
 }
 
 func (c *Context) SaveFile(fileHeader *multipart.FileHeader, path string) error {
-	return c.Ctx.SaveFile(fileHeader, path)
+	return c.Ctx.SaveUploadedFile(fileHeader, path)
 }
 
 func (c *Context) SendStream(code int, contentType string, stream io.Reader) error {
-	return c.Ctx.Stream(code, contentType, stream)
+	c.Ctx.DataFromReader(code, -1, contentType, stream, nil)
+	return nil
 }
 
 func (c *Context) JSON(code int, data interface{}) error {
-	c.Ctx.JSON(code, data)
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.Ctx.Data(code, "application/json; charset=utf-8", b)
 	return nil
 }
 
+// Body вернуть тело запроса, тело читается один раз и сохраняется в контексте
 func (c *Context) Body() []byte {
-	body := c.Ctx.Request().Body
-	b, _ := ioutil.ReadAll(body)
-	defer body.Close()
-	return b
+	if c.body == nil && c.Ctx.Request.Body != nil {
+		c.body, _ = c.Ctx.GetRawData()
+		c.Ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	}
+	return c.body
 }
 
 func (c *Context) BodyParser(out interface{}) error {
-	return c.Ctx.Bind(out)
+	c.Body()
+	return c.Ctx.ShouldBind(out)
 }
 
 func (c *Context) QueryParam(name string, defaultValue ...string) string {
@@ -109,11 +169,11 @@ func (c *Context) QueryParam(name string, defaultValue ...string) string {
 }
 
 func (c *Context) QueryValues() url.Values {
-	return c.Ctx.QueryParams()
+	return c.Ctx.Request.URL.Query()
 }
 
 func (c *Context) QueryParams(h func(key, value string)) {
-	for k, v := range c.Ctx.QueryParams() {
+	for k, v := range c.Ctx.Request.URL.Query() {
 		s := ""
 		if len(v) > 0 {
 			s = v[0]
@@ -123,7 +183,7 @@ func (c *Context) QueryParams(h func(key, value string)) {
 }
 
 func (c *Context) Hostname() string {
-	return c.Ctx.Host
+	return c.Ctx.Request.Host
 }
 
 func (c *Context) FormValue(name string) string {
@@ -135,10 +195,12 @@ func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
 }
 
 func (c *Context) Scheme() string {
-	return c.Ctx.Scheme()
+	if c.Ctx.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
 }
 
 func (c *Context) MultipartForm() (*multipart.Form, error) {
 	return c.Ctx.MultipartForm()
 }
-*/
@@ -8,6 +8,7 @@ require (
 	github.com/gofiber/template v1.6.27
 	github.com/labstack/echo/v4 v4.7.2
 	github.com/mustan989/jsonschema v0.4.1
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -45,5 +46,4 @@ require (
 	golang.org/x/sys v0.0.0-20220227234510-4e6760a101f9 // indirect
 	golang.org/x/text v0.3.7 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -0,0 +1,159 @@
+package egowebapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var pathParamRegexp = regexp.MustCompile(`{(\w+)}`)
+
+// LintIssue замечание к документу swagger
+type LintIssue struct {
+	Path    string
+	Method  string
+	Message string
+}
+
+// LintIssues список замечаний, реализует error для строгого режима
+type LintIssues []LintIssue
+
+func (i LintIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	if i.Method == "" {
+		return fmt.Sprintf("%s: %s", i.Path, i.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", strings.ToUpper(i.Method), i.Path, i.Message)
+}
+
+func (l LintIssues) Error() string {
+	s := make([]string, len(l))
+	for i, issue := range l {
+		s[i] = issue.String()
+	}
+	return "swagger: " + strings.Join(s, "; ")
+}
+
+// addDangling Запоминаем ссылку на незарегистрированную модель
+func (s *Swagger) addDangling(path, method, where, ref string) {
+	if ref == "" {
+		return
+	}
+	s.dangling = append(s.dangling, LintIssue{
+		Path:    path,
+		Method:  method,
+		Message: fmt.Sprintf("%s refers to unknown model %q", where, ref),
+	})
+}
+
+// Lint Проверка сформированного документа: ссылки на незарегистрированные модели,
+// повторяющиеся идентификаторы операций, несоответствие параметров пути шаблону,
+// ответы без описания, неиспользуемые модели и тэги без описания
+func (s *Swagger) Lint() LintIssues {
+
+	issues := append(LintIssues{}, s.dangling...)
+
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ids := map[string]string{}
+	tags := map[string]bool{}
+	for _, path := range paths {
+
+		// Параметры из шаблона пути
+		var template []string
+		inTemplate := map[string]bool{}
+		for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+			if !inTemplate[match[1]] {
+				template = append(template, match[1])
+			}
+			inTemplate[match[1]] = true
+		}
+
+		item := s.Paths[path]
+		methods := make([]string, 0, len(item))
+		for method := range item {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := item[method]
+			issue := func(format string, a ...interface{}) {
+				issues = append(issues, LintIssue{Path: path, Method: method, Message: fmt.Sprintf(format, a...)})
+			}
+
+			if operation.ID != "" {
+				if prev, ok := ids[operation.ID]; ok {
+					issue("operation id %q is already used by %s", operation.ID, prev)
+				} else {
+					ids[operation.ID] = strings.ToUpper(method) + " " + path
+				}
+			}
+
+			declared := map[string]bool{}
+			for _, param := range operation.Parameters {
+				if param == nil || param.In != InPath {
+					continue
+				}
+				declared[param.Name] = true
+				if !inTemplate[param.Name] {
+					issue("path parameter %q is not in the path", param.Name)
+				}
+			}
+			for _, name := range template {
+				if !declared[name] {
+					issue("path parameter %q is not declared", name)
+				}
+			}
+
+			codes := make([]string, 0, len(operation.Responses))
+			for code := range operation.Responses {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			for _, code := range codes {
+				if r := operation.Responses[code]; r == nil || r.Description == "" {
+					issue("response %s has no description", code)
+				}
+			}
+
+			for _, tag := range operation.Tags {
+				tags[tag] = true
+			}
+		}
+	}
+
+	// Модели, которые не попали в определения
+	for _, name := range sortedModels(s.models) {
+		if _, ok := s.Definitions[name]; !ok {
+			issues = append(issues, LintIssue{Message: fmt.Sprintf("model %q is not used", name)})
+		}
+	}
+
+	// Тэги используемые в операциях, в том числе не объявленные в списке тэгов
+	described := map[string]bool{}
+	for _, tag := range s.Tags {
+		if tag.Description != "" {
+			described[tag.Name] = true
+		}
+	}
+	used := make([]string, 0, len(tags))
+	for tag := range tags {
+		used = append(used, tag)
+	}
+	sort.Strings(used)
+	for _, tag := range used {
+		if !described[tag] {
+			issues = append(issues, LintIssue{Message: fmt.Sprintf("tag %q has no description", tag)})
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,101 @@
+package egowebapi
+
+import (
+	"strings"
+	"testing"
+)
+
+type Shelf struct{}
+
+func (Shelf) Get(route *Route) {
+	route.SetParameters(NewPathParam("/{id}")).
+		SetResponse(200, "Person", nil, "Person").
+		SetEmptyParam("List").SetResponseArray(200, "Person", nil, "People")
+	route.Handler = func(c *Context) error {
+		return nil
+	}
+}
+
+func (Shelf) Post(route *Route) {
+	route.SetParameters(&Parameter{Path: "/{id}", Name: "uid", In: InPath, Type: TypeString}, NewBodyParam(true, "Persn", false)).
+		SetResponse(201, "", nil)
+	route.Handler = func(c *Context) error {
+		return nil
+	}
+}
+
+type Slot struct{}
+
+func (Slot) Get(route *Route) {
+	route.Handler = func(c *Context) error {
+		return nil
+	}
+}
+
+func TestSwagger_Lint(t *testing.T) {
+
+	s, _ := newTestServer(Config{StrictSwagger: true})
+	s.Swagger.SetModel("Person", Person{}).SetModel("Address", Address{}).SetModel("Unused", Shelf{})
+	s.Register(new(Shelf)).SetPath("/shelf")
+	s.Register(new(Slot)).SetPath("/shelf-{id}").SetDescription("Slot")
+
+	err := s.Build()
+	if err == nil {
+		t.Fatal("strict mode must fail")
+	}
+
+	issues := map[string]bool{}
+	for _, issue := range err.(LintIssues) {
+		issues[issue.String()] = true
+	}
+	for _, want := range []string{
+		`POST shelf/{id}: body parameter body refers to unknown model "Persn"`,
+		`POST shelf/{id}: path parameter "uid" is not in the path`,
+		`POST shelf/{id}: path parameter "id" is not declared`,
+		`POST shelf/{id}: response 201 has no description`,
+		`GET shelf/{id}: operation id "getshelf-{id}" is already used by GET shelf-{id}`,
+		`GET shelf-{id}: path parameter "id" is not declared`,
+		`model "Unused" is not used`,
+		`tag "shelf/shelf" has no description`,
+	} {
+		if !issues[want] {
+			t.Errorf("missing issue %s in %v", want, err)
+		}
+	}
+
+	// Неудачный Build откатывается, без строгого режима документ формируется заново
+	if len(s.Swagger.Paths) != 0 {
+		t.Errorf("paths after failed build %v", s.Swagger.Paths)
+	}
+	s.Config.StrictSwagger = false
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ссылки сохраняются при повторном добавлении операции и каждая модель получает свое имя
+	if r := s.Swagger.Paths["shelf"]["get"].Responses["200"]; r.Schema == nil || r.Schema.Items.Ref != RefDefinitions+"Person" {
+		t.Errorf("list response %+v", r)
+	}
+	if r := s.Swagger.Paths["shelf/{id}"]["get"].Responses["200"]; r.Schema == nil || r.Schema.Ref != RefDefinitions+"Person" {
+		t.Errorf("response %+v", r)
+	}
+	if _, ok := s.Swagger.Definitions["Address"]; !ok {
+		t.Errorf("definitions %v", s.Swagger.Definitions)
+	}
+	if len(issues) != 8 {
+		t.Errorf("issues %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "swagger: ") {
+		t.Error(err)
+	}
+
+	// Тэг операции, не объявленный в списке тэгов
+	orphan := &Swagger{Paths: Paths{"pets": PathItem{"get": Operation{
+		ID:        "getpets",
+		Tags:      []string{"pets"},
+		Responses: map[string]*Response{"200": {Description: "OK"}},
+	}}}}
+	if issues := orphan.Lint(); len(issues) != 1 || issues[0].String() != `tag "pets" has no description` {
+		t.Errorf("undeclared tag: %v", issues)
+	}
+}
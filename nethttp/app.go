@@ -0,0 +1,146 @@
+package nethttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Server адаптер веб сервера на основе стандартной библиотеки net/http.
+// Реализует http.Handler, поэтому может быть встроен в существующий стек обработчиков
+type Server struct {
+	App        *http.Server
+	Renderer   Renderer
+	router     *Router
+	middleware []func(http.Handler) http.Handler
+	once       sync.Once
+}
+
+// HandlerFunc обработчик запроса, возвращающий ошибку
+type HandlerFunc func(c *Context) error
+
+// ServeHTTP реализация http.Handler, ошибка обработчика возвращается клиенту с кодом 500,
+// если ответ еще не был отправлен
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c := NewContext(w, r)
+	if err := h(c); err != nil && !c.writer.written {
+		http.Error(c.writer, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) getRouter() *Router {
+	s.once.Do(func() {
+		if s.router == nil {
+			s.router = &Router{}
+		}
+	})
+	return s.router
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Renderer != nil {
+		r = r.WithContext(context.WithValue(r.Context(), rendererKey, s.Renderer))
+	}
+	var h http.Handler = s.getRouter()
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	h.ServeHTTP(w, r)
+}
+
+func (s *Server) Start(addr string) error {
+	err := s.server(addr).ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) StartTLS(addr, cert, key string) error {
+	// Запускаем слушатель с TLS настройкой
+	err := s.server(addr).ListenAndServeTLS(cert, key)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	if s.App == nil {
+		return nil
+	}
+	return s.App.Shutdown(ctx)
+}
+
+func (s *Server) Static(prefix, root string) {
+	h := http.StripPrefix(strings.TrimRight(prefix, "/"), http.FileServer(http.Dir(root)))
+	path := strings.TrimRight(prefix, "/") + "/*"
+	s.getRouter().Handle(http.MethodGet, path, h)
+	s.getRouter().Handle(http.MethodHead, path, h)
+}
+
+func (s *Server) Any(path string, handler interface{}) {
+	s.getRouter().Handle(methodAny, path, toHandler(handler))
+}
+
+// Use добавить промежуточные обработчики вида func(http.Handler) http.Handler,
+// другой тип вызывает panic
+func (s *Server) Use(params ...interface{}) {
+	for _, param := range params {
+		m, ok := param.(func(http.Handler) http.Handler)
+		if !ok {
+			panic(fmt.Sprintf("nethttp: unsupported middleware type %T", param))
+		}
+		s.middleware = append(s.middleware, m)
+	}
+}
+
+func (s *Server) Add(method, path string, handler interface{}) {
+	s.getRouter().Handle(method, path, toHandler(handler))
+}
+
+// GetApp вернуть http.Handler сервера
+func (s *Server) GetApp() interface{} {
+	return s
+}
+
+func (s *Server) NotFoundPage(path, page string) {
+	s.getRouter().NotFound = HandlerFunc(func(c *Context) error {
+		return c.render(http.StatusNotFound, page, nil)
+	})
+}
+
+func (s *Server) ConvertParam(param string) string {
+	return "{" + param + "}"
+}
+
+// server инициализация http.Server, если он не был передан
+func (s *Server) server(addr string) *http.Server {
+	if s.App == nil {
+		s.App = &http.Server{}
+	}
+	if s.App.Handler == nil {
+		s.App.Handler = s
+	}
+	s.App.Addr = addr
+	return s.App
+}
+
+// toHandler приведение обработчика к http.Handler. Обработчик неподдерживаемого
+// типа вызывает panic при добавлении маршрута, а не при первом запросе
+func toHandler(handler interface{}) http.Handler {
+	switch h := handler.(type) {
+	case HandlerFunc:
+		return h
+	case func(c *Context) error:
+		return HandlerFunc(h)
+	case http.Handler:
+		return h
+	case func(http.ResponseWriter, *http.Request):
+		return http.HandlerFunc(h)
+	}
+	panic(fmt.Sprintf("nethttp: unsupported handler type %T", handler))
+}
@@ -0,0 +1,92 @@
+package nethttp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+
+	server := &Server{}
+	server.Add(http.MethodGet, "/api/user/"+server.ConvertParam("id"), HandlerFunc(func(c *Context) error {
+		return c.JSON(200, map[string]string{"id": c.Params("id"), "q": c.QueryParam("q")})
+	}))
+	server.Add(http.MethodGet, "/api/error", HandlerFunc(func(c *Context) error {
+		return http.ErrNoCookie
+	}))
+
+	tests := []struct {
+		method string
+		target string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/api/user/10?q=test", 200, `{"id":"10","q":"test"}`},
+		{http.MethodGet, "/api/user/10/20", 404, ""},
+		{http.MethodPost, "/api/user/10", 405, ""},
+		{http.MethodGet, "/api/error", 500, http.ErrNoCookie.Error()},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(test.method, test.target, nil))
+		if w.Code != test.status {
+			t.Errorf("%s %s: status %d, want %d", test.method, test.target, w.Code, test.status)
+		}
+		b, _ := ioutil.ReadAll(w.Body)
+		if test.body != "" && strings.TrimSpace(string(b)) != test.body {
+			t.Errorf("%s %s: body %s, want %s", test.method, test.target, b, test.body)
+		}
+	}
+}
+
+func TestRouter_Priority(t *testing.T) {
+
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name))
+		})
+	}
+	orders := [][]string{
+		{"/users/{id}", "/users/me", "/users/*"},
+		{"/users/*", "/users/me", "/users/{id}"},
+	}
+	for _, order := range orders {
+		router := &Router{}
+		for _, path := range order {
+			router.Handle(http.MethodGet, path, handler(path))
+		}
+		for target, want := range map[string]string{
+			"/users/me":    "/users/me",
+			"/users/10":    "/users/{id}",
+			"/users/10/20": "/users/*",
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			if w.Body.String() != want {
+				t.Errorf("order %v: %s served by %s, want %s", order, target, w.Body.String(), want)
+			}
+		}
+	}
+}
+
+func TestServer_Unsupported(t *testing.T) {
+
+	for name, register := range map[string]func(s *Server){
+		"handler":    func(s *Server) { s.Add(http.MethodGet, "/", func() {}) },
+		"any":        func(s *Server) { s.Any("/", nil) },
+		"middleware": func(s *Server) { s.Use(func(c *Context) error { return nil }) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", name)
+				}
+			}()
+			register(&Server{})
+		}()
+	}
+}
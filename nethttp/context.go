@@ -0,0 +1,283 @@
+package nethttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const defaultMaxMemory = 32 << 20
+
+type Context struct {
+	Writer  http.ResponseWriter
+	Request *http.Request
+	writer  *responseWriter
+	params  Params
+	body    []byte
+}
+
+// responseWriter отслеживает отправку ответа клиенту
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	w.status = code
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// NewContext инициализация контекста запроса
+func NewContext(w http.ResponseWriter, r *http.Request) *Context {
+	rw, ok := w.(*responseWriter)
+	if !ok {
+		rw = &responseWriter{ResponseWriter: w}
+	}
+	return &Context{
+		Writer:  rw,
+		Request: r,
+		writer:  rw,
+		params:  getParams(r),
+	}
+}
+
+func (c *Context) Render(name string, data interface{}, layouts ...string) error {
+	return c.render(http.StatusOK, name, data, layouts...)
+}
+
+func (c *Context) render(code int, name string, data interface{}, layouts ...string) error {
+	renderer, ok := c.Request.Context().Value(rendererKey).(Renderer)
+	if !ok {
+		return errors.New("Не указан шаблонизатор - Renderer")
+	}
+	buf := &bytes.Buffer{}
+	if err := renderer.Render(buf, name, data, layouts...); err != nil {
+		return err
+	}
+	return c.Send(code, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (c *Context) Params(key string, defaultValue ...string) string {
+	value := c.params[key]
+	if value == "" && defaultValue != nil {
+		return defaultValue[0]
+	}
+	return value
+}
+
+func (c *Context) Get(key string, defaultValue ...string) string {
+	value := c.Request.Header.Get(key)
+	if value == "" && defaultValue != nil {
+		return defaultValue[0]
+	}
+	return value
+}
+
+func (c *Context) Set(key, value string) {
+	c.Writer.Header().Set(key, value)
+}
+
+func (c *Context) SendStatus(code int) error {
+	c.Writer.WriteHeader(code)
+	return nil
+}
+
+func (c *Context) Cookies(key string) string {
+	cookie, err := c.Request.Cookie(key)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.Writer, cookie)
+}
+
+func (c *Context) ClearCookie(key string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:    key,
+		Value:   "",
+		Path:    "/",
+		MaxAge:  -1,
+		Expires: time.Unix(0, 0),
+	})
+}
+
+func (c *Context) Redirect(location string, status int) error {
+	http.Redirect(c.Writer, c.Request, location, status)
+	return nil
+}
+
+func (c *Context) Path() string {
+	return c.Request.URL.Path
+}
+
+func (c *Context) Method() string {
+	return c.Request.Method
+}
+
+// RequestURI путь запроса вместе с параметрами адресной строки, как его передал клиент
+func (c *Context) RequestURI() string {
+	if c.Request.RequestURI != "" {
+		return c.Request.RequestURI
+	}
+	return c.Request.URL.RequestURI()
+}
+
+func (c *Context) SendString(code int, s string) error {
+	return c.Send(code, "text/plain; charset=utf-8", []byte(s))
+}
+
+func (c *Context) Send(code int, contentType string, b []byte) error {
+	if contentType != "" {
+		c.Writer.Header().Set("Content-Type", contentType)
+	}
+	c.Writer.WriteHeader(code)
+	_, err := c.Writer.Write(b)
+	return err
+}
+
+func (c *Context) SendFile(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+	http.ServeFile(c.Writer, c.Request, file)
+	return nil
+}
+
+func (c *Context) SaveFile(fh *multipart.FileHeader, path string) error {
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, f)
+	return err
+}
+
+func (c *Context) SendStream(code int, contentType string, stream io.Reader) error {
+	if contentType != "" {
+		c.Writer.Header().Set("Content-Type", contentType)
+	}
+	c.Writer.WriteHeader(code)
+	_, err := io.Copy(c.Writer, stream)
+	return err
+}
+
+func (c *Context) JSON(code int, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.Send(code, "application/json", b)
+}
+
+// Body вернуть тело запроса, тело читается один раз и сохраняется в контексте
+func (c *Context) Body() []byte {
+	if c.body == nil && c.Request.Body != nil {
+		c.body, _ = ioutil.ReadAll(c.Request.Body)
+		c.Request.Body.Close()
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	}
+	return c.body
+}
+
+func (c *Context) BodyParser(out interface{}) error {
+	ct, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	switch ct {
+	case "", "application/json":
+		return json.Unmarshal(c.Body(), out)
+	case "application/xml", "text/xml":
+		return xml.Unmarshal(c.Body(), out)
+	}
+	return fmt.Errorf("Неподдерживаемый тип содержимого: %s", ct)
+}
+
+func (c *Context) QueryParam(name string, defaultValue ...string) string {
+	value := c.Request.URL.Query().Get(name)
+	if value == "" && defaultValue != nil {
+		return defaultValue[0]
+	}
+	return value
+}
+
+func (c *Context) QueryValues() url.Values {
+	return c.Request.URL.Query()
+}
+
+func (c *Context) QueryParams(h func(key, value string)) {
+	for k, v := range c.Request.URL.Query() {
+		s := ""
+		if len(v) > 0 {
+			s = v[0]
+		}
+		h(k, s)
+	}
+}
+
+func (c *Context) Hostname() string {
+	return c.Request.Host
+}
+
+func (c *Context) FormValue(name string) string {
+	return c.Request.FormValue(name)
+}
+
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	f, fh, err := c.Request.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return fh, nil
+}
+
+func (c *Context) Scheme() string {
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func (c *Context) MultipartForm() (*multipart.Form, error) {
+	if err := c.Request.ParseMultipartForm(defaultMaxMemory); err != nil {
+		return nil, err
+	}
+	return c.Request.MultipartForm, nil
+}
@@ -0,0 +1,157 @@
+package nethttp
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Params параметры пути запроса
+type Params map[string]string
+
+type contextKey int
+
+const (
+	paramsKey contextKey = iota
+	rendererKey
+)
+
+const methodAny = "*"
+
+// Router простой маршрутизатор с поддержкой параметров пути вида {name}
+// и завершающего шаблона *. Как и в других адаптерах, статический сегмент
+// имеет приоритет над параметром, а параметр над шаблоном *, независимо от порядка добавления
+type Router struct {
+	routes   []*route
+	NotFound http.Handler
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  http.Handler
+}
+
+// Handle добавить обработчик для метода и пути
+func (r *Router) Handle(method, path string, handler http.Handler) {
+	r.routes = append(r.routes, &route{
+		method:   method,
+		segments: split(path),
+		handler:  handler,
+	})
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return r.routes[i].before(r.routes[j])
+	})
+}
+
+// ServeHTTP поиск маршрута и вызов обработчика
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	segments := split(req.URL.Path)
+	var allow []string
+
+	for _, rt := range r.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		if rt.method != methodAny && rt.method != req.Method {
+			allow = append(allow, rt.method)
+			continue
+		}
+		if len(params) > 0 {
+			req = req.WithContext(context.WithValue(req.Context(), paramsKey, params))
+		}
+		rt.handler.ServeHTTP(w, req)
+		return
+	}
+
+	// Путь существует, но метод не поддерживается
+	if allow != nil {
+		sort.Strings(allow)
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.NotFound != nil {
+		r.NotFound.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// before приоритет маршрута: первый различающийся по виду сегмент решает,
+// статический раньше параметра, параметр раньше шаблона *
+func (rt *route) before(other *route) bool {
+	for i := 0; i < len(rt.segments) && i < len(other.segments); i++ {
+		a, b := segmentKind(rt.segments[i]), segmentKind(other.segments[i])
+		if a != b {
+			return a < b
+		}
+	}
+	return false
+}
+
+// segmentKind вид сегмента шаблона: 0 - статический, 1 - параметр, 2 - шаблон *
+func segmentKind(s string) int {
+	switch {
+	case s == "*":
+		return 2
+	case len(s) > 2 && s[0] == '{' && s[len(s)-1] == '}':
+		return 1
+	}
+	return 0
+}
+
+// match сравнение пути запроса с шаблоном маршрута
+func (rt *route) match(segments []string) (Params, bool) {
+
+	var params Params
+	for i, s := range rt.segments {
+		// Шаблон * захватывает оставшуюся часть пути
+		if s == "*" {
+			if params == nil {
+				params = Params{}
+			}
+			params["*"] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		if len(s) > 2 && s[0] == '{' && s[len(s)-1] == '}' {
+			if params == nil {
+				params = Params{}
+			}
+			params[s[1:len(s)-1]] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+	if len(rt.segments) != len(segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// split разбиваем путь на сегменты
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// getParams извлекаем параметры пути из контекста запроса
+func getParams(req *http.Request) Params {
+	if params, ok := req.Context().Value(paramsKey).(Params); ok {
+		return params
+	}
+	return nil
+}
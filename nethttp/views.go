@@ -0,0 +1,60 @@
+package nethttp
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"path/filepath"
+)
+
+// Renderer интерфейс шаблонизатора
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}, layouts ...string) error
+}
+
+// Views шаблонизатор на основе html/template
+type Views struct {
+	Root      string
+	Extension string
+	Layout    string
+}
+
+const Html = ".html"
+
+func (v *Views) Render(w io.Writer, name string, data interface{}, layouts ...string) error {
+
+	if name == "" {
+		return errors.New("Имя не может быть пустым")
+	}
+
+	layout := v.Layout
+	if len(layouts) > 0 {
+		layout = layouts[0]
+	}
+
+	var files []string
+	if layout != "" {
+		files = append(files, filepath.Join(v.Root, layout+v.Extension))
+	}
+	files = append(files, filepath.Join(v.Root, name+v.Extension))
+
+	t, err := template.ParseFiles(files...)
+	if err != nil {
+		return err
+	}
+	if layout != "" {
+		return t.ExecuteTemplate(w, filepath.Base(layout+v.Extension), data)
+	}
+	return t.ExecuteTemplate(w, filepath.Base(name+v.Extension), data)
+}
+
+func NewViews(root string, extension string, layout ...string) Renderer {
+	v := &Views{
+		Root:      root,
+		Extension: extension,
+	}
+	if len(layout) > 0 {
+		v.Layout = layout[0]
+	}
+	return v
+}
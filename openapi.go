@@ -0,0 +1,479 @@
+package egowebapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/consts"
+	"github.com/egovorukhin/egowebapi/security"
+	"strings"
+)
+
+// Версии формата документа
+const (
+	OpenAPI20 = "2.0"
+	OpenAPI30 = "3.0.3"
+	OpenAPI31 = "3.1.0"
+
+	RefComponentsSchemas = "#/components/schemas/"
+)
+
+type OpenAPI struct {
+	OpenAPI      string                     `json:"openapi"`
+	Info         *Info                      `json:"info,omitempty"`
+	Servers      []OpenAPIServer            `json:"servers,omitempty"`
+	Paths        map[string]OpenAPIPathItem `json:"paths"`
+	Components   *Components                `json:"components,omitempty"`
+	Security     Security                   `json:"security,omitempty"`
+	Tags         []Tag                      `json:"tags,omitempty"`
+	ExternalDocs *ExternalDocs              `json:"externalDocs,omitempty"`
+}
+
+type OpenAPIServer struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+type OpenAPIOperation struct {
+	Tags            []string                    `json:"tags,omitempty"`
+	Summary         string                      `json:"summary,omitempty"`
+	Description     string                      `json:"description,omitempty"`
+	ExternalDocs    *ExternalDocs               `json:"externalDocs,omitempty"`
+	ID              string                      `json:"operationId,omitempty"`
+	Parameters      []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody     *RequestBody                `json:"requestBody,omitempty"`
+	Responses       map[string]*OpenAPIResponse `json:"responses"`
+	Deprecated      bool                        `json:"deprecated,omitempty"`
+	Security        Security                    `json:"security,omitempty"`
+	Roles           []string                    `json:"x-required-roles,omitempty"`
+	ControllerRoles []string                    `json:"x-controller-roles,omitempty"`
+	Scopes          []string                    `json:"x-required-scopes,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name            string         `json:"name"`
+	In              string         `json:"in"`
+	Description     string         `json:"description,omitempty"`
+	Required        bool           `json:"required,omitempty"`
+	AllowEmptyValue bool           `json:"allowEmptyValue,omitempty"`
+	Explode         *bool          `json:"explode,omitempty"`
+	Schema          *OpenAPISchema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+type OpenAPIResponse struct {
+	Description string                   `json:"description"`
+	Headers     map[string]OpenAPIHeader `json:"headers,omitempty"`
+	Content     map[string]MediaType     `json:"content,omitempty"`
+}
+
+type OpenAPIHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPISchema схема параметров, заголовков и ссылок на модели.
+// Type может быть строкой или списком типов для 3.1
+type OpenAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty"`
+	Type       interface{}               `json:"type,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+	Nullable   bool                      `json:"nullable,omitempty"`
+	Items      *OpenAPISchema            `json:"items,omitempty"`
+	Enum       []interface{}             `json:"enum,omitempty"`
+	Default    interface{}               `json:"default,omitempty"`
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]json.RawMessage `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme  `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string      `json:"type"`
+	Description  string      `json:"description,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	In           string      `json:"in,omitempty"`
+	Scheme       string      `json:"scheme,omitempty"`
+	BearerFormat string      `json:"bearerFormat,omitempty"`
+	Flows        *OAuthFlows `json:"flows,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// SetOpenAPIVersion Устанавливаем версию формируемого документа: OpenAPI20, OpenAPI30 или OpenAPI31
+func (s *Swagger) SetOpenAPIVersion(version string) *Swagger {
+	s.version = version
+	return s
+}
+
+// isOpenAPI3 Документ формируется в формате OpenAPI 3
+func (s *Swagger) isOpenAPI3() bool {
+	return strings.HasPrefix(s.version, "3")
+}
+
+// OpenAPI Преобразование документа swagger 2.0 в OpenAPI 3.
+// Если версия не указана, то формируется 3.0
+func (s *Swagger) OpenAPI() (*OpenAPI, error) {
+
+	version := s.version
+	if !strings.HasPrefix(version, "3") {
+		version = OpenAPI30
+	}
+	c := openAPIConverter{
+		swagger: s,
+		is31:    strings.HasPrefix(version, "3.1"),
+	}
+
+	doc := &OpenAPI{
+		OpenAPI:      version,
+		Info:         s.Info,
+		Servers:      c.servers(),
+		Paths:        map[string]OpenAPIPathItem{},
+		Security:     s.Security,
+		Tags:         s.Tags,
+		ExternalDocs: s.ExternalDocs,
+	}
+
+	for path, item := range s.Paths {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = OpenAPIPathItem{}
+		}
+		for method, operation := range item {
+			doc.Paths[path][method] = c.operation(operation)
+		}
+	}
+
+	components := &Components{}
+	if len(s.Definitions) > 0 {
+		components.Schemas = map[string]json.RawMessage{}
+		for name, definition := range s.Definitions {
+			b, err := json.Marshal(definition)
+			if err != nil {
+				return nil, err
+			}
+			components.Schemas[name] = bytes.ReplaceAll(b, []byte(`"`+RefDefinitions), []byte(`"`+RefComponentsSchemas))
+		}
+	}
+	if len(s.SecurityDefinitions) > 0 {
+		components.SecuritySchemes = map[string]SecurityScheme{}
+		for name, definition := range s.SecurityDefinitions {
+			components.SecuritySchemes[name] = securityScheme(name, definition)
+		}
+	}
+	if components.Schemas != nil || components.SecuritySchemes != nil {
+		doc.Components = components
+	}
+
+	return doc, nil
+}
+
+// openAPIConverter преобразование элементов swagger 2.0 в OpenAPI 3
+type openAPIConverter struct {
+	swagger *Swagger
+	is31    bool
+}
+
+// servers адреса серверов из схем, хоста и базового пути
+func (c openAPIConverter) servers() (servers []OpenAPIServer) {
+
+	basePath := strings.TrimRight(c.swagger.BasePath, "/")
+	if c.swagger.Host == "" {
+		if basePath != "" {
+			servers = append(servers, OpenAPIServer{URL: basePath})
+		}
+		return
+	}
+
+	schemes := c.swagger.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	for _, scheme := range schemes {
+		servers = append(servers, OpenAPIServer{URL: scheme + "://" + c.swagger.Host + basePath})
+	}
+
+	return
+}
+
+// operation преобразование операции: параметры body и formData переносятся в requestBody
+func (c openAPIConverter) operation(o Operation) *OpenAPIOperation {
+
+	op := &OpenAPIOperation{
+		Tags:            o.Tags,
+		Summary:         o.Summary,
+		Description:     o.Description,
+		ExternalDocs:    o.ExternalDocs,
+		ID:              o.ID,
+		Deprecated:      o.Deprecated,
+		Security:        o.Security,
+		Roles:           o.RequiredRoles,
+		ControllerRoles: o.ControllerRoles,
+		Scopes:          o.RequiredScopes,
+		Responses:       map[string]*OpenAPIResponse{},
+	}
+
+	consumes := o.Consumes
+	if len(consumes) == 0 {
+		consumes = c.swagger.Consumes
+	}
+	if len(consumes) == 0 {
+		consumes = []string{consts.MIMEApplicationJSON}
+	}
+
+	var form *OpenAPISchema
+	isMultipart := false
+	for _, param := range o.Parameters {
+		if param == nil {
+			continue
+		}
+		switch param.In {
+		case InBody:
+			schema := c.schema(param.Schema)
+			op.RequestBody = &RequestBody{
+				Description: param.Description,
+				Required:    param.Required,
+				Content:     c.content(consumes, schema),
+			}
+		case InFormData:
+			if form == nil {
+				form = &OpenAPISchema{
+					Type:       TypeObject,
+					Properties: map[string]*OpenAPISchema{},
+				}
+			}
+			property := c.paramSchema(param)
+			if param.Type == TypeFile {
+				isMultipart = true
+				property = &OpenAPISchema{Type: TypeString, Format: "binary"}
+			}
+			form.Properties[param.Name] = property
+			if param.Required {
+				form.Required = append(form.Required, param.Name)
+			}
+		default:
+			p := &OpenAPIParameter{
+				Name:            param.Name,
+				In:              param.In,
+				Description:     param.Description,
+				Required:        param.Required || param.In == InPath,
+				AllowEmptyValue: param.AllowEmptyValue,
+				Schema:          c.paramSchema(param),
+			}
+			if param.CollectionFormat == CollectionFormatMulti {
+				explode := true
+				p.Explode = &explode
+			}
+			op.Parameters = append(op.Parameters, p)
+		}
+	}
+
+	if form != nil && op.RequestBody == nil {
+		mime := consts.MIMEApplicationForm
+		if isMultipart {
+			mime = consts.MIMEMultipartForm
+		}
+		op.RequestBody = &RequestBody{
+			Required: len(form.Required) > 0,
+			Content:  map[string]MediaType{mime: {Schema: form}},
+		}
+	}
+
+	produces := o.Produces
+	if len(produces) == 0 {
+		produces = c.swagger.Produces
+	}
+	if len(produces) == 0 {
+		produces = []string{consts.MIMEApplicationJSON}
+	}
+
+	for code, response := range o.Responses {
+		if response == nil {
+			continue
+		}
+		r := &OpenAPIResponse{
+			Description: response.Description,
+		}
+		if response.Schema != nil {
+			r.Content = c.content(produces, c.schema(response.Schema))
+		}
+		if len(response.Headers) > 0 {
+			r.Headers = map[string]OpenAPIHeader{}
+			for name, header := range response.Headers {
+				r.Headers[name] = OpenAPIHeader{
+					Description: header.Description,
+					Schema:      c.simpleSchema(header.SimpleSchema, header.Enum),
+				}
+			}
+		}
+		op.Responses[code] = r
+	}
+
+	return op
+}
+
+// content схема для каждого типа содержимого
+func (c openAPIConverter) content(mimes []string, schema *OpenAPISchema) map[string]MediaType {
+	content := map[string]MediaType{}
+	for _, mime := range mimes {
+		content[mime] = MediaType{Schema: schema}
+	}
+	return content
+}
+
+// schema ссылка на модель или массив моделей
+func (c openAPIConverter) schema(s *Schema) *OpenAPISchema {
+	if s == nil {
+		return nil
+	}
+	schema := &OpenAPISchema{
+		Ref: convertRef(s.Ref),
+	}
+	if s.Type != "" {
+		schema.Type = s.Type
+	}
+	if s.Items != nil {
+		schema.Items = &OpenAPISchema{Ref: convertRef(s.Items.Ref)}
+		if s.Items.Ref == "" {
+			schema.Items = c.simpleSchema(s.Items.SimpleSchema, s.Items.Enum)
+		}
+	}
+	return schema
+}
+
+// paramSchema схема параметра
+func (c openAPIConverter) paramSchema(p *Parameter) *OpenAPISchema {
+
+	t := p.Type
+	if t == "" {
+		t = TypeString
+	}
+	schema := &OpenAPISchema{
+		Type:   t,
+		Format: p.Format,
+	}
+	if p.Items == nil {
+		return schema
+	}
+
+	items := c.simpleSchema(p.Items.SimpleSchema, p.Items.Enum)
+	if t == TypeArray {
+		schema.Items = items
+		return schema
+	}
+	// Перечисление значений для простого параметра
+	schema.Enum = items.Enum
+	schema.Default = items.Default
+	return schema
+}
+
+// simpleSchema схема простого типа, признак nullable в 3.1 задается списком типов
+func (c openAPIConverter) simpleSchema(s SimpleSchema, enum []interface{}) *OpenAPISchema {
+
+	schema := &OpenAPISchema{
+		Format:  s.Format,
+		Enum:    enum,
+		Default: s.Default,
+	}
+	if s.Type != "" {
+		schema.Type = s.Type
+	}
+	if s.Nullable {
+		if c.is31 && s.Type != "" {
+			schema.Type = []string{s.Type, "null"}
+		} else {
+			schema.Nullable = true
+		}
+	}
+	if s.Items != nil {
+		schema.Items = c.simpleSchema(s.Items.SimpleSchema, s.Items.Enum)
+	}
+	return schema
+}
+
+// convertRef замена ссылки на определение swagger 2.0 ссылкой на компонент
+func convertRef(ref string) string {
+	if strings.HasPrefix(ref, RefDefinitions) {
+		return RefComponentsSchemas + strings.TrimPrefix(ref, RefDefinitions)
+	}
+	return ref
+}
+
+// securityScheme преобразование описания авторизации
+func securityScheme(name string, d security.Definition) SecurityScheme {
+
+	scheme := SecurityScheme{
+		Type:        d.Type,
+		Description: d.Description,
+	}
+
+	switch d.Type {
+	case security.TypeBasic:
+		scheme.Type = "http"
+		scheme.Scheme = "basic"
+		if d.Scheme != "" {
+			scheme.Scheme = d.Scheme
+		}
+	case security.TypeApiKey:
+		scheme.Name = d.Name
+		scheme.In = d.In
+		if d.BearerFormat != "" {
+			scheme.Type = "http"
+			scheme.Scheme = "bearer"
+			scheme.BearerFormat = d.BearerFormat
+			scheme.Name, scheme.In = "", ""
+		}
+	case security.TypeOAuth2:
+		flow := &OAuthFlow{
+			AuthorizationURL: d.AuthorizationURL,
+			TokenURL:         d.TokenURL,
+			Scopes:           d.Scopes,
+		}
+		if flow.Scopes == nil {
+			flow.Scopes = map[string]string{}
+		}
+		scheme.Flows = &OAuthFlows{}
+		switch d.Flow {
+		case "implicit":
+			scheme.Flows.Implicit = flow
+		case "password":
+			scheme.Flows.Password = flow
+		case "application":
+			scheme.Flows.ClientCredentials = flow
+		default:
+			scheme.Flows.AuthorizationCode = flow
+		}
+	case "":
+		// Схемы без типа описываем через http по имени
+		scheme.Type = "http"
+		scheme.Scheme = strings.ToLower(name)
+	}
+
+	return scheme
+}
@@ -0,0 +1,114 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/security"
+	"testing"
+)
+
+type Members struct{}
+
+func (Members) Post(route *Route) {
+	route.SetSecurity(security.BasicAuth, security.DigestAuth).
+		SetParameters(NewPathParam("/{group}"), NewHeaderParam("X-Trace", false), NewBodyParam(true, "Person", false)).
+		SetResponse(201, "Person", Headers{"Location": NewHeader("", true)}, "Created")
+	route.Handler = func(c *Context) error {
+		return nil
+	}
+}
+
+func TestSwagger_OpenAPI(t *testing.T) {
+
+	s, _ := newTestServer(Config{
+		Port: 8080,
+		Authorization: security.Authorization{
+			Basic: &security.Basic{},
+			Digest: &security.Digest{
+				Handler: func(user string, advanced security.Advanced) (string, bool) {
+					return "", false
+				},
+			},
+		},
+	})
+	s.Swagger.SetBasePath("/api").SetModel("Person", Person{}).SetOpenAPIVersion(OpenAPI31)
+	s.Register(new(Members)).SetPath("/api/members")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := s.Swagger.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		OpenAPI string          `json:"openapi"`
+		Servers []OpenAPIServer `json:"servers"`
+		Paths   map[string]map[string]struct {
+			Parameters  []OpenAPIParameter `json:"parameters"`
+			RequestBody struct {
+				Required bool `json:"required"`
+				Content  map[string]struct {
+					Schema OpenAPISchema `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Headers map[string]struct {
+					Schema OpenAPISchema `json:"schema"`
+				} `json:"headers"`
+			} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas         map[string]json.RawMessage `json:"schemas"`
+			SecuritySchemes map[string]SecurityScheme  `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != OpenAPI31 {
+		t.Errorf("openapi %s", doc.OpenAPI)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "http://localhost:8080/api" {
+		t.Errorf("servers %v", doc.Servers)
+	}
+	post, ok := doc.Paths["/members/{group}"]["post"]
+	if !ok {
+		t.Fatalf("paths %s", b)
+	}
+	if len(post.Parameters) != 2 || post.Parameters[0].In != InPath || post.Parameters[1].In != InHeader {
+		t.Errorf("parameters %+v", post.Parameters)
+	}
+	if ref := post.RequestBody.Content["application/json"].Schema.Ref; !post.RequestBody.Required || ref != "#/components/schemas/Person" {
+		t.Errorf("request body ref %s", ref)
+	}
+	if tp, _ := post.Responses["201"].Headers["Location"].Schema.Type.([]interface{}); len(tp) != 2 {
+		t.Errorf("nullable header type %v", post.Responses["201"].Headers["Location"].Schema.Type)
+	}
+	if _, ok := doc.Components.Schemas["Address"]; !ok {
+		t.Errorf("schemas %v", doc.Components.Schemas)
+	}
+	if sc := doc.Components.SecuritySchemes[security.BasicAuth]; sc.Type != "http" || sc.Scheme != "basic" {
+		t.Errorf("security scheme %+v", sc)
+	}
+	if sc := doc.Components.SecuritySchemes[security.DigestAuth]; sc.Type != "http" || sc.Scheme != "digest" {
+		t.Errorf("digest security scheme %+v", sc)
+	}
+	// В swagger 2.0 Digest описывается допустимым типом basic с расширением
+	if d := s.Swagger.SecurityDefinitions[security.DigestAuth]; d.Type != security.TypeBasic || d.Scheme != security.SchemeDigest {
+		t.Errorf("digest security definition %+v", d)
+	}
+
+	// Ссылки внутри определений
+	var person struct {
+		Properties map[string]struct {
+			Items OpenAPISchema `json:"items"`
+		} `json:"properties"`
+	}
+	if err = json.Unmarshal(doc.Components.Schemas["Person"], &person); err != nil {
+		t.Fatal(err)
+	}
+	if ref := person.Properties["addresses"].Items.Ref; ref != "#/components/schemas/Address" {
+		t.Errorf("nested ref %s", ref)
+	}
+}
@@ -17,6 +17,13 @@ type Operation struct {
 	Security     Security             `json:"security,omitempty"`
 	Parameters   []*Parameter         `json:"parameters,omitempty"`
 	Responses    map[string]*Response `json:"responses,omitempty"`
+	// RequiredRoles роли маршрута, хотя бы одна из которых необходима для доступа
+	RequiredRoles []string `json:"x-required-roles,omitempty"`
+	// ControllerRoles роли контроллера, хотя бы одна из которых необходима
+	// дополнительно к ролям маршрута
+	ControllerRoles []string `json:"x-controller-roles,omitempty"`
+	// RequiredScopes области доступа, необходимые все
+	RequiredScopes []string `json:"x-required-scopes,omitempty"`
 }
 
 type Schema struct {
@@ -199,7 +206,7 @@ func setTypeFormat(t interface{}) (Type string, Format string) {
 		break
 	case time.Time, *time.Time:
 		Type = TypeString
-		Format = "date-time"
+		Format = FormatDateTime
 		break
 	case bool, *bool:
 		Type = TypeBoolean
@@ -1,16 +1,43 @@
 package egowebapi
 
-// Permission структура описывает разрешения на запрос
+import "github.com/egovorukhin/egowebapi/security"
+
+// Permission структура описывает разрешения на запрос. Если указана Policy, то доступ
+// определяется политикой, иначе IdentityHandler или Handler. Запрос без идентификации
+// проверяется только политикой, при использовании обработчиков он запрещается
 type Permission struct {
 	AllRoutes            bool
+	Policy               *Policy
 	Handler              PermissionHandler
+	IdentityHandler      IdentityPermissionHandler
 	NotPermissionHandler ErrorHandler
 }
 
-// check Проверяем запрос на разрешения
-func (p *Permission) check(username string, path string) bool {
-	if p.Handler == nil {
-		return true
+// Explain Проверка запроса с описанием причины решения. Политика сопоставляется
+// с методом и шаблоном пути маршрута route вида /api/user/{id}, что не зависит
+// от веб сервера, обработчики получают путь запроса path. Без политики и обработчиков
+// запрос запрещается, так как маршрут требует проверки доступа
+func (p *Permission) Explain(identity *security.Identity, method, route, path string) Decision {
+	if p.Policy != nil {
+		return p.Policy.Explain(identity, method, route)
+	}
+	d := Decision{Index: -1}
+	switch {
+	case identity == nil:
+		d.Reason = "request without identity is denied"
+	case p.IdentityHandler != nil:
+		d.Allowed = p.IdentityHandler(identity, path)
+		d.Reason = "checked by identity handler"
+	case p.Handler != nil:
+		d.Allowed = p.Handler(identity.Username, path)
+		d.Reason = "checked by handler"
+	default:
+		d.Reason = "no permission policy or handler, denied"
 	}
-	return p.Handler(username, path)
+	return d
+}
+
+// check Проверяем запрос на разрешения
+func (p *Permission) check(identity *security.Identity, method, route, path string) bool {
+	return p.Explain(identity, method, route, path).Allowed
 }
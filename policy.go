@@ -0,0 +1,280 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/egovorukhin/egowebapi/security"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Действие правила политики
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Особые значения субъекта правила
+const (
+	// SubjectAny любой авторизованный пользователь
+	SubjectAny = "*"
+	// SubjectAnonymous запрос без идентификации
+	SubjectAnonymous = "anonymous"
+)
+
+// policyReloadInterval минимальный интервал проверки изменения файла политики
+const policyReloadInterval = time.Second
+
+// Rule правило политики доступа. Пустые Subject и Role подходят любому запросу,
+// Method через запятую или "*", Path - шаблон пути, где "*" и "{name}" соответствуют
+// одному сегменту, а "**" - любому количеству сегментов
+type Rule struct {
+	Subject string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Role    string `json:"role,omitempty" yaml:"role,omitempty"`
+	Method  string `json:"method,omitempty" yaml:"method,omitempty"`
+	Path    string `json:"path" yaml:"path"`
+	Effect  string `json:"effect" yaml:"effect"`
+}
+
+func (r Rule) String() string {
+	subject := r.Subject
+	if r.Role != "" {
+		subject = "role:" + r.Role
+	}
+	if subject == "" {
+		subject = "all"
+	}
+	method := r.Method
+	if method == "" {
+		method = "*"
+	}
+	return fmt.Sprintf("%s %s %s %s", r.Effect, subject, method, r.Path)
+}
+
+// Decision результат проверки политики
+type Decision struct {
+	Allowed bool
+	// Rule сработавшее правило, nil если ни одно правило не подошло
+	Rule *Rule
+	// Index номер правила в политике, -1 если правило не найдено
+	Index  int
+	Reason string
+}
+
+func (d Decision) String() string {
+	return d.Reason
+}
+
+// Policy политика доступа. Запрещающие правила имеют приоритет над разрешающими,
+// при отсутствии подходящего правила доступ запрещен
+type Policy struct {
+	mu      sync.RWMutex
+	rules   []Rule
+	path    string
+	modTime time.Time
+	checked time.Time
+}
+
+// NewPolicy Инициализация политики из списка правил
+func NewPolicy(rules ...Rule) (*Policy, error) {
+	p := &Policy{}
+	if err := p.SetRules(rules...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewPolicyFile Инициализация политики из файла yaml или json. Файл перечитывается
+// при изменении, ошибка чтения измененного файла оставляет прежние правила
+func NewPolicyFile(path string) (*Policy, error) {
+	p := &Policy{path: path}
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetRules заменить правила политики
+func (p *Policy) SetRules(rules ...Rule) error {
+	for i, rule := range rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return fmt.Errorf("policy rule %d: unknown effect %q", i, rule.Effect)
+		}
+		if rule.Path == "" {
+			return fmt.Errorf("policy rule %d: path is empty", i)
+		}
+	}
+	p.mu.Lock()
+	p.rules = append([]Rule{}, rules...)
+	p.mu.Unlock()
+	return nil
+}
+
+// Rules текущие правила политики
+func (p *Policy) Rules() []Rule {
+	p.refresh()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]Rule{}, p.rules...)
+}
+
+// Explain проверка запроса с указанием сработавшего правила
+func (p *Policy) Explain(identity *security.Identity, method, path string) Decision {
+
+	p.refresh()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	allow := -1
+	for i := range p.rules {
+		rule := p.rules[i]
+		if !rule.match(identity, method, path) {
+			continue
+		}
+		if rule.Effect == EffectDeny {
+			return Decision{Rule: &rule, Index: i, Reason: fmt.Sprintf("denied by rule %d (%s)", i, rule)}
+		}
+		if allow < 0 {
+			allow = i
+		}
+	}
+	if allow >= 0 {
+		rule := p.rules[allow]
+		return Decision{Allowed: true, Rule: &rule, Index: allow, Reason: fmt.Sprintf("allowed by rule %d (%s)", allow, rule)}
+	}
+	return Decision{Index: -1, Reason: "no matching rule, denied by default"}
+}
+
+// Allow проверка запроса
+func (p *Policy) Allow(identity *security.Identity, method, path string) bool {
+	return p.Explain(identity, method, path).Allowed
+}
+
+// refresh перечитать файл политики, если он изменился
+func (p *Policy) refresh() {
+	if p.path == "" {
+		return
+	}
+	p.mu.Lock()
+	if time.Since(p.checked) < policyReloadInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.checked = time.Now()
+	modTime := p.modTime
+	p.mu.Unlock()
+
+	if info, err := os.Stat(p.path); err == nil && !info.ModTime().Equal(modTime) {
+		_ = p.reload()
+	}
+}
+
+// reload прочитать правила из файла
+func (p *Policy) reload() error {
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+
+	doc := struct {
+		Rules []Rule `json:"rules" yaml:"rules"`
+	}{}
+	switch strings.ToLower(filepath.Ext(p.path)) {
+	case ".json":
+		err = json.Unmarshal(b, &doc)
+	default:
+		err = yaml.Unmarshal(b, &doc)
+	}
+	if err == nil {
+		err = p.SetRules(doc.Rules...)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s", p.path, err)
+	}
+
+	p.mu.Lock()
+	p.modTime = info.ModTime()
+	p.checked = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// match соответствие правила запросу
+func (r Rule) match(identity *security.Identity, method, path string) bool {
+
+	switch r.Subject {
+	case "":
+	case SubjectAny:
+		if identity == nil {
+			return false
+		}
+	case SubjectAnonymous:
+		if identity != nil {
+			return false
+		}
+	default:
+		if identity == nil || identity.Username != r.Subject {
+			return false
+		}
+	}
+	if r.Role != "" && (identity == nil || !identity.HasRole(r.Role)) {
+		return false
+	}
+
+	if r.Method != "" && r.Method != "*" {
+		found := false
+		for _, m := range strings.Split(r.Method, ",") {
+			if strings.EqualFold(strings.TrimSpace(m), method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return matchPath(splitPath(r.Path), splitPath(path))
+}
+
+// matchPath сопоставление сегментов шаблона и пути
+func matchPath(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if segment == "**" {
+			for j := i; j <= len(path); j++ {
+				if matchPath(pattern[i+1:], path[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if i >= len(path) {
+			return false
+		}
+		if segment == "*" || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			continue
+		}
+		if segment != path[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,151 @@
+package egowebapi
+
+import (
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"github.com/egovorukhin/egowebapi/security"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const policyYAML = `rules:
+  - role: admin
+    path: /api/**
+    effect: allow
+  - subject: "*"
+    method: GET
+    path: /api/users/{id}
+    effect: allow
+  - subject: guest
+    path: /api/users/*
+    effect: deny
+  - subject: anonymous
+    method: GET, HEAD
+    path: /public/**
+    effect: allow
+`
+
+func TestPolicy_Explain(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := ioutil.WriteFile(path, []byte(policyYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPolicyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := &security.Identity{Username: "root", Roles: []string{"admin"}}
+	user := &security.Identity{Username: "user"}
+	guest := &security.Identity{Username: "guest"}
+
+	tests := []struct {
+		identity *security.Identity
+		method   string
+		path     string
+		allowed  bool
+		index    int
+	}{
+		{admin, "DELETE", "/api/users/1", true, 0},
+		{admin, "GET", "/api", true, 0},
+		{user, "GET", "/api/users/1", true, 1},
+		{user, "get", "/api/users/1/", true, 1},
+		{user, "PUT", "/api/users/1", false, -1},
+		{user, "GET", "/api/users/1/roles", false, -1},
+		{guest, "GET", "/api/users/1", false, 2},
+		{nil, "GET", "/api/users/1", false, -1},
+		{nil, "HEAD", "/public/css/site.css", true, 3},
+		{user, "GET", "/public/css/site.css", false, -1},
+	}
+	for _, test := range tests {
+		d := policy.Explain(test.identity, test.method, test.path)
+		if d.Allowed != test.allowed || d.Index != test.index {
+			t.Errorf("%v %s %s: %s, want allowed %v by rule %d", test.identity, test.method, test.path, d, test.allowed, test.index)
+		}
+	}
+
+	// Изменение файла применяется без перезапуска
+	rules := `{"rules": [{"subject": "user", "method": "PUT", "path": "/api/users/{id}", "effect": "allow"}]}`
+	jsonPath := filepath.Join(filepath.Dir(path), "policy.json")
+	if err = ioutil.WriteFile(jsonPath, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(jsonPath, path); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	_ = os.Chtimes(path, later, later)
+	policy.checked = time.Time{}
+	if d := policy.Explain(user, "PUT", "/api/users/1"); !d.Allowed || d.Rule.Subject != "user" {
+		t.Errorf("reloaded policy: %s", d)
+	}
+
+	if _, err = NewPolicy(Rule{Path: "/", Effect: "permit"}); err == nil {
+		t.Error("unknown effect is accepted")
+	}
+}
+
+type Item struct{}
+
+func (Item) Get(route *Route) {
+	route.SetSecurity(security.BasicAuth).Permission().
+		SetParameters(NewPathParam("/{id}")).
+		SetEmptyParam("List")
+	route.Handler = func(c *Context) error {
+		return c.SendStatus(200)
+	}
+}
+
+func TestPermission_Route(t *testing.T) {
+
+	// Правило с конкретным путем не совпадает с шаблоном маршрута
+	policy, err := NewPolicy(
+		Rule{Subject: SubjectAny, Path: "/items/1", Effect: EffectDeny},
+		Rule{Subject: SubjectAny, Method: "GET", Path: "/items/{id}", Effect: EffectAllow},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		permission *Permission
+		target     string
+		status     int
+	}{
+		{&Permission{Policy: policy}, "/items/1", 200},
+		{&Permission{Policy: policy}, "/items", 403},
+		{&Permission{}, "/items/1", 403},
+	} {
+		web := &nethttp.Server{}
+		s := New(web, Config{
+			Authorization: security.Authorization{
+				Basic: &security.Basic{
+					Handler: func(user string, pass string) bool {
+						return true
+					},
+				},
+			},
+			Permission: test.permission,
+			ContextHandler: func(handler Handler) interface{} {
+				return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+					return handler(NewContext(c))
+				})
+			},
+		})
+		s.Register(new(Item)).SetPath("/items")
+		if err = s.Build(); err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest("GET", test.target, nil)
+		r.SetBasicAuth("user", "")
+		w := httptest.NewRecorder()
+		web.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%+v %s: status %d, want %d", test.permission, test.target, w.Code, test.status)
+		}
+	}
+}
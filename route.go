@@ -1,10 +1,14 @@
 package egowebapi
 
 import (
+	"errors"
+	"fmt"
 	"github.com/egovorukhin/egowebapi/consts"
 	"github.com/egovorukhin/egowebapi/security"
-	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,8 +16,14 @@ type Route struct {
 	emptyPathParam *EmptyPathParam
 	session        SessionTurn
 	isPermission   bool
+	isValidation   bool
+	body           *bodySchema
 	models         Models
-	Handler        Handler
+	middleware     []Middleware
+	err            error
+	// defaultSecurity метод Authorization.AllRoutes, добавленный по умолчанию
+	defaultSecurity string
+	Handler         Handler
 	Operation
 }
 
@@ -167,9 +177,16 @@ func (r *Route) SetSummary(s string) *Route {
 	return r
 }*/
 
-// SetSecurity указываем метод авторизации
+// SetSecurity указываем методы авторизации, каждый из которых является
+// альтернативой (ИЛИ): для доступа достаточно пройти любой из них
 func (r *Route) SetSecurity(security ...string) *Route {
 	for _, sec := range security {
+		if sec == r.defaultSecurity {
+			r.defaultSecurity = ""
+		}
+		if r.hasRequirement(sec) {
+			continue
+		}
 		r.Security = append(r.Security, map[string][]string{
 			sec: {},
 		})
@@ -178,6 +195,79 @@ func (r *Route) SetSecurity(security ...string) *Route {
 	return r
 }
 
+// SetSecurityAll указываем методы авторизации, которые должны быть пройдены
+// одновременно (И). Несколько вызовов добавляют несколько альтернатив.
+// Метод Authorization.AllRoutes по умолчанию при этом не является альтернативой
+func (r *Route) SetSecurityAll(security ...string) *Route {
+	r.removeDefaultSecurity()
+	requirement := map[string][]string{}
+	for _, sec := range security {
+		requirement[sec] = []string{}
+	}
+	r.Security = append(r.Security, requirement)
+	return r
+}
+
+// SetScopes указываем области доступа OAuth2, необходимые для маршрута.
+// Если авторизация OAuth2 для маршрута не указана, то она добавляется
+// вместо метода Authorization.AllRoutes по умолчанию
+func (r *Route) SetScopes(scopes ...string) *Route {
+	if r.defaultSecurity != security.OAuth2Auth {
+		r.removeDefaultSecurity()
+	}
+	found := false
+	for _, sec := range r.Security {
+		if _, ok := sec[security.OAuth2Auth]; ok {
+			sec[security.OAuth2Auth] = append(sec[security.OAuth2Auth], scopes...)
+			found = true
+		}
+	}
+	if !found {
+		r.Security = append(r.Security, map[string][]string{
+			security.OAuth2Auth: scopes,
+		})
+	}
+	return r
+}
+
+// removeDefaultSecurity удаление альтернативы Authorization.AllRoutes, добавленной по умолчанию
+func (r *Route) removeDefaultSecurity() {
+	if r.defaultSecurity == "" {
+		return
+	}
+	for i, requirement := range r.Security {
+		if _, ok := requirement[r.defaultSecurity]; ok && len(requirement) == 1 {
+			r.Security = append(r.Security[:i], r.Security[i+1:]...)
+			break
+		}
+	}
+	r.defaultSecurity = ""
+}
+
+// hasRequirement есть ли альтернатива из единственного метода авторизации
+func (r *Route) hasRequirement(sec string) bool {
+	for _, requirement := range r.Security {
+		if _, ok := requirement[sec]; ok && len(requirement) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRoles указываем роли, хотя бы одна из которых должна быть у пользователя.
+// При отсутствии ролей запрос завершается с кодом 403
+func (r *Route) RequireRoles(roles ...string) *Route {
+	r.RequiredRoles = appendUnique(r.RequiredRoles, roles...)
+	return r
+}
+
+// RequireScopes указываем области доступа, которые все должны быть у пользователя.
+// При отсутствии областей запрос завершается с кодом 403
+func (r *Route) RequireScopes(scopes ...string) *Route {
+	r.RequiredScopes = appendUnique(r.RequiredScopes, scopes...)
+	return r
+}
+
 // Session вешаем получение аутентификации сессии,
 func (r *Route) Session(t ...SessionTurn) *Route {
 	if t == nil {
@@ -194,19 +284,211 @@ func (r *Route) Permission() *Route {
 	return r
 }
 
+// Validation ставим флаг для проверки параметров запроса по их описанию.
+// Для всех маршрутов проверку можно включить в конфигурации
+func (r *Route) Validation() *Route {
+	r.isValidation = true
+	return r
+}
+
 // EmptyHandler пустой обработчик
 func (r *Route) EmptyHandler() {
 	r.Handler = nil
 }
 
+// Use Добавить промежуточные обработчики маршрута
+func (r *Route) Use(middleware ...Middleware) *Route {
+	r.middleware = append(r.middleware, middleware...)
+	return r
+}
+
 // SetHandler устанавливаем обработчик
 func (r *Route) SetHandler(handler Handler) *Route {
 	r.Handler = handler
 	return r
 }
 
-// getHandler возвращаем обработчик основанный на параметрах конфигурации маршрута
-func (r *Route) getHandler(config Config, swagger *Swagger) Handler {
+// SetTypedHandler устанавливаем типизированный обработчик вида
+// func(c *Context, in *In) (*Out, error). Входная модель заполняется из параметров пути,
+// адресной строки и заголовков по тэгам ewa, тело запроса записывается в поле с тэгом ewa:"body:required".
+// Параметры, модели и ответы добавляются в swagger автоматически.
+// Допустимы также сигнатуры без входной модели и без результата
+func (r *Route) SetTypedHandler(fn interface{}) *Route {
+
+	h, err := newTypedHandler(fn)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	// Параметры входной модели
+	if h.in != nil {
+		in := reflect.New(h.in.Elem()).Elem()
+		r.SetParameters(ModelToParameters(in.Interface())...)
+		for i := 0; i < in.NumField(); i++ {
+			field := in.Type().Field(i)
+			for _, tag := range parseBindTags(field) {
+				if tag.in != InBody {
+					continue
+				}
+				name, isArray, err := r.models.modelName(field.Type)
+				if err != nil {
+					r.err = err
+					return r
+				}
+				r.SetParameters(NewBodyParam(tag.required, name, isArray))
+			}
+		}
+		r.setResponse(400, "", false, nil, "Invalid request parameters")
+	}
+
+	// Модель ответа. Пустой указатель или интерфейс отправляется как 204
+	if h.hasOut {
+		name, isArray, err := r.models.modelName(h.out)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		r.setResponse(200, name, isArray, nil, "OK")
+		if h.out.Kind() == reflect.Ptr || h.out.Kind() == reflect.Interface {
+			r.setResponse(204, "", false, nil, "No Content")
+		}
+	}
+
+	r.Handler = h.handler
+	return r
+}
+
+// authenticate проверка авторизации по семантике swagger: достаточно пройти любую из
+// альтернатив, внутри альтернативы необходимо пройти все методы. Методы альтернативы проверяются
+// в алфавитном порядке, идентификация берется от первого из них. Если ни одна альтернатива
+// не пройдена, то в WWW-Authenticate перечисляются вызовы всех не пройденных методов
+// в порядке объявления альтернатив
+func (r *Route) authenticate(c *Context, auth security.Authorization) (*security.Identity, error) {
+
+	var (
+		errs       security.Errors
+		challenges []string
+		digest     *security.DigestChallenge
+		digestAt   int
+	)
+	for _, requirement := range r.Security {
+		var identity *security.Identity
+		failed := false
+		for _, key := range sortedSchemes(requirement) {
+			id, challenge, err := authenticateScheme(c, auth, key, requirement[key])
+			if err != nil {
+				failed = true
+				errs = append(errs, err)
+				if digest == nil && errors.As(err, &digest) {
+					digestAt = len(challenges)
+					challenges = append(challenges, "")
+				}
+				if challenge != "" && !contains(challenges, challenge) {
+					challenges = append(challenges, challenge)
+				}
+				continue
+			}
+			if identity == nil {
+				identity = id
+			}
+		}
+		if !failed {
+			return identity, nil
+		}
+	}
+
+	// Nonce выдается только для отправляемого вызова Digest
+	if digest != nil {
+		challenge, err := digest.Challenge()
+		if err != nil {
+			return nil, err
+		}
+		challenges[digestAt] = challenge
+	}
+	if len(challenges) > 0 {
+		c.Set(consts.HeaderWWWAuthenticate, strings.Join(challenges, ", "))
+	}
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, errs
+}
+
+// authenticateScheme проверка одного метода авторизации. Возвращает вызов
+// для заголовка WWW-Authenticate, если метод его поддерживает
+func authenticateScheme(c *Context, auth security.Authorization, key string, scopes []string) (identity *security.Identity, challenge string, err error) {
+
+	header := c.Get(consts.HeaderAuthorization)
+	switch key {
+	case security.BasicAuth:
+		if auth.Basic == nil {
+			break
+		}
+		auth.Basic.SetHeader(header)
+		if identity, err = auth.Basic.Do(); err != nil {
+			challenge = err.Error()
+		}
+		return
+	case security.DigestAuth:
+		if auth.Digest == nil {
+			break
+		}
+		// Вызов с новым nonce формируется в authenticate, только если он будет отправлен
+		identity, err = auth.Digest.Verify(c.Method(), c.RequestURI(), header, c.Body())
+		return
+	case security.OAuth2Auth:
+		if auth.OAuth2 == nil {
+			break
+		}
+		// Ошибка сервера проверки токена не сопровождается вызовом Bearer
+		var bearer *security.BearerChallenge
+		if identity, err = auth.OAuth2.Verify(header, scopes); errors.As(err, &bearer) {
+			challenge = bearer.Challenge
+		}
+		return
+	case security.JWTAuth:
+		if auth.JWT == nil {
+			break
+		}
+		if identity, err = auth.JWT.Verify(header); err != nil {
+			challenge = err.Error()
+		}
+		return
+	case security.ApiKeyAuth:
+		if auth.ApiKey == nil {
+			break
+		}
+		a := auth.ApiKey
+		var value string
+		switch a.Param {
+		// Если не нашли в заголовке, то ищем в переменных запроса адресной строки
+		case security.ParamQuery:
+			value = c.QueryParam(a.KeyName)
+		// Пытаемся получить из заголовка токен
+		case security.ParamHeader:
+			value = c.Get(a.KeyName)
+		}
+		identity, err = a.SetValue(value).Do()
+		return
+	}
+
+	return nil, "", fmt.Errorf("authorization %s is not configured", key)
+}
+
+// sortedSchemes методы авторизации альтернативы в алфавитном порядке
+func sortedSchemes(requirement map[string][]string) []string {
+	keys := make([]string, 0, len(requirement))
+	for key := range requirement {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getHandler возвращаем обработчик основанный на параметрах конфигурации маршрута.
+// Метод и шаблон пути вида /api/user/{id} используются для проверки политики доступа
+func (r *Route) getHandler(config Config, swagger *Swagger, method, path string) Handler {
 
 	return func(c *Context) error {
 
@@ -217,64 +499,42 @@ func (r *Route) getHandler(config Config, swagger *Swagger) Handler {
 			err        error
 			isSecurity bool
 		)
-		for _, sec := range r.Security {
-			for key := range sec {
-				switch key {
-				case security.BasicAuth:
-					if config.Authorization.Basic != nil {
-						config.Authorization.Basic.SetHeader(c.Get(consts.HeaderAuthorization))
-						c.Identity, err = config.Authorization.Basic.Do()
-						if err != nil {
-							c.Set(consts.HeaderWWWAuthenticate, err.Error())
-						}
-					}
-				case security.DigestAuth:
-					if config.Authorization.Digest != nil {
-						c.Identity, err = config.Authorization.Digest.Do()
-					}
-				case security.ApiKeyAuth:
-					if config.Authorization.ApiKey != nil {
-						a := config.Authorization.ApiKey
-						var value string
-						switch a.Param {
-						// Если не нашли в заголовке, то ищем в переменных запроса адресной строки
-						case security.ParamQuery:
-							value = c.QueryParam(a.KeyName)
-							break
-						// Пытаемся получить из заголовка токен
-						case security.ParamHeader:
-							value = c.Get(a.KeyName)
-							break
-						}
-						c.Identity, err = a.SetValue(value).Do()
-					}
-				}
-				if err == nil {
-					isSecurity = true
-				}
-			}
+		if len(r.Security) > 0 {
+			c.Identity, err = r.authenticate(c, config.Authorization)
+			isSecurity = err == nil
 		}
 
 		// Проверка на сессию
-		if config.Session != nil && r.session != None {
+		if config.Session != nil {
 			keyName := config.Session.KeyName
 			switch r.session {
+			case None:
+				// Маршрут без проверки сессии загружает ее данные при первом обращении через LoadSession
+				c.loadSession = func() *Session {
+					value := sessionCookie(c, config.Session)
+					if value == "" {
+						return nil
+					}
+					data, e := config.Session.Load(value)
+					if e != nil {
+						return nil
+					}
+					return newSession(keyName, value, data)
+				}
 			case Is:
 				if isSecurity {
 					break
 				}
-				c.Identity, err = config.Session.Check(c.Cookies(keyName))
-				if c.Session != nil {
-					c.Session.LastTime = time.Now()
+				value := sessionCookie(c, config.Session)
+				c.Identity, err = config.Session.Check(value)
+				if err != nil {
+					break
+				}
+				if data, e := config.Session.Load(value); e == nil {
+					c.Session = newSession(keyName, value, data)
 				}
 			case On:
 				value := config.Session.GenSessionIdHandler()
-				cookie := &http.Cookie{
-					Name:    keyName,
-					Value:   value,
-					Expires: time.Now().Add(config.Session.Expires),
-				}
-				c.SetCookie(cookie)
 				now := time.Now()
 				c.Session = &Session{
 					Key:      keyName,
@@ -283,15 +543,48 @@ func (r *Route) getHandler(config Config, swagger *Swagger) Handler {
 					LastTime: now,
 				}
 			case Off:
-				c.Identity, err = config.Session.Check(c.Cookies(keyName))
-				c.ClearCookie(config.Session.KeyName)
+				value := sessionCookie(c, config.Session)
+				c.Identity, err = config.Session.Check(value)
+				if e := config.Session.Delete(value); e != nil {
+					return e
+				}
+				clearSessionCookie(c, config.Session, 0)
 				c.Session = nil
 				return c.Redirect(config.Session.RedirectPath, config.Session.RedirectStatus)
 			}
+			// Сессия в cookie записывается перед отправкой ответа, пока заголовки не отправлены
+			if config.Session.Cookie != nil && (c.Session != nil || c.loadSession != nil) {
+				force := r.session == On
+				c.commit = func() error {
+					return c.saveSessionCookie(config.Session, force)
+				}
+			}
+			// Новая сессия в хранилище сохраняется вместе с cookie, только если в ней есть данные
+			if config.Session.Cookie == nil && r.session == On {
+				c.commit = func() error {
+					return c.saveSessionStore(config.Session)
+				}
+			}
+		}
+
+		// Дополняем идентификацию ролями и утверждениями
+		if err == nil && c.Identity != nil && config.Authorization.Identify != nil {
+			err = config.Authorization.Identify(c.Identity)
 		}
 
 		// Проверка на ошибку авторизации и отправку кода 401
 		if err != nil {
+			// Токен не удалось проверить по вине сервера проверки
+			if errors.Is(err, security.ErrValidatorUnavailable) {
+				return c.SendStatus(consts.StatusServiceUnavailable)
+			}
+			if errors.Is(err, security.ErrValidatorFailed) {
+				return c.SendStatus(consts.StatusInternalServerError)
+			}
+			// Токен действителен, но не хватает областей доступа
+			if errors.Is(err, security.ErrInsufficientScope) {
+				return c.SendStatus(consts.StatusForbidden)
+			}
 			if r.session != None {
 				// Если cookie не существует, то перенаправляем запрос условно на "/login"
 				return c.Redirect(config.Session.RedirectPath, config.Session.RedirectStatus)
@@ -302,19 +595,62 @@ func (r *Route) getHandler(config Config, swagger *Swagger) Handler {
 			return c.SendStatus(consts.StatusUnauthorized)
 		}
 
+		// Требования к ролям и областям доступа
+		if len(r.ControllerRoles) > 0 || len(r.RequiredRoles) > 0 || len(r.RequiredScopes) > 0 {
+			if c.Identity == nil {
+				return c.SendStatus(consts.StatusUnauthorized)
+			}
+			if (len(r.ControllerRoles) > 0 && !c.Identity.HasRole(r.ControllerRoles...)) ||
+				(len(r.RequiredRoles) > 0 && !c.Identity.HasRole(r.RequiredRoles...)) ||
+				!c.Identity.HasScopes(r.RequiredScopes...) {
+				if config.Permission != nil && config.Permission.NotPermissionHandler != nil {
+					return config.Permission.NotPermissionHandler(c, consts.StatusForbidden, "Forbidden")
+				}
+				return c.SendStatus(consts.StatusForbidden)
+			}
+		}
+
 		// Доступ к маршрутам
 		if r.isPermission && config.Permission != nil {
-			if c.Identity != nil {
-				if !config.Permission.check(c.Identity.Username, c.Path()) {
-					if config.Permission.NotPermissionHandler != nil {
-						return config.Permission.NotPermissionHandler(c, consts.StatusForbidden, "Forbidden")
-					}
-					return c.SendStatus(consts.StatusForbidden)
+			if !config.Permission.check(c.Identity, method, path, c.Path()) {
+				// Без идентификации отказ означает необходимость авторизации
+				if c.Identity == nil {
+					return c.SendStatus(consts.StatusUnauthorized)
+				}
+				if config.Permission.NotPermissionHandler != nil {
+					return config.Permission.NotPermissionHandler(c, consts.StatusForbidden, "Forbidden")
+				}
+				return c.SendStatus(consts.StatusForbidden)
+			}
+		}
+
+		// Проверка параметров запроса
+		if r.isValidation || config.Validation {
+			if errs := validateParameters(c, r.Parameters); errs != nil {
+				return NewError(consts.StatusBadRequest, ValidationError{Errors: errs})
+			}
+			if r.body != nil {
+				if errs := r.body.validate(c.Body()); errs != nil {
+					return NewError(consts.StatusUnprocessableEntity, ValidationError{Errors: errs})
 				}
 			}
 		}
 
 		// Обычный маршрут
-		return r.Handler(c)
+		if err = r.Handler(c); err != nil {
+			return err
+		}
+
+		// Сессия в cookie, если обработчик не отправил ответ
+		if err = c.commitSession(); err != nil {
+			return err
+		}
+
+		// Сохраняем данные сессии, измененные после отправки ответа
+		if config.Session != nil && c.Session != nil && c.Session.changed {
+			return config.Session.Save(c.Session.Value, c.Session.data())
+		}
+
+		return nil
 	}
 }
@@ -1,12 +1,50 @@
 package security
 
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDigestRealm        = "egowebapi"
+	DefaultDigestNonceExpires = 5 * time.Minute
+)
+
+const (
+	QopAuth    = "auth"
+	QopAuthInt = "auth-int"
+)
+
+// Digest авторизация по RFC 7616. Пустые поля заполняются значениями по умолчанию:
+// Realm - DefaultDigestRealm, Algorithms - SHA-256 и MD5, Qop - auth,
+// NonceExpires - DefaultDigestNonceExpires, Store - хранилище в памяти на
+// DefaultNonceStoreSize значений. Handler обязателен
 type Digest struct {
-	Handler DigestAuthHandler
+	Realm        string
+	Algorithms   []string
+	Qop          []string
+	Opaque       string
+	NonceExpires time.Duration
+	Store        NonceStore
+	Handler      DigestAuthHandler
+
+	once sync.Once
 }
 
+// Advanced параметры заголовка Authorization
 type Advanced struct {
 	Realm       string
 	Nonce       string
+	Uri         string
 	Algorithm   string
 	Qop         string
 	NonceCount  string
@@ -14,7 +52,8 @@ type Advanced struct {
 	Opaque      string
 }
 
-type DigestAuthHandler func(user string, pass string, advanced Advanced) bool
+// DigestAuthHandler возвращает пароль пользователя, ok = false если пользователь не найден
+type DigestAuthHandler func(user string, advanced Advanced) (password string, ok bool)
 
 const (
 	Md5Algorithm           = "MD5"
@@ -25,10 +64,309 @@ const (
 	Sha512256SessAlgorithm = "SHA-512-256-sess"
 )
 
-func (Digest) Do() (identity *Identity, err error) {
-	return
+// DigestChallenge ошибка авторизации. Вызов для заголовка WWW-Authenticate с новым
+// nonce формируется методом Challenge, только когда он действительно отправляется клиенту
+type DigestChallenge struct {
+	Stale bool
+	Err   error
+
+	digest *Digest
+}
+
+func (e *DigestChallenge) Error() string {
+	if e.Err != nil {
+		return "digest: " + e.Err.Error()
+	}
+	return "digest: authorization required"
+}
+
+// Challenge новый nonce и вызов для каждого алгоритма. Признак stale сообщает
+// клиенту, что учетные данные верны, но nonce устарел
+func (e *DigestChallenge) Challenge() (string, error) {
+
+	d := e.digest
+	nonce, err := newNonce()
+	if err == nil {
+		err = d.store().Add(nonce, time.Now().Add(d.nonceExpires()))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var challenges []string
+	for _, algorithm := range d.algorithms() {
+		c := `Digest realm="` + d.realm() + `", qop="` + strings.Join(d.qop(), ", ") +
+			`", algorithm=` + algorithm + `, nonce="` + nonce + `", opaque="` + d.opaque() + `"`
+		if e.Stale {
+			c += ", stale=true"
+		}
+		challenges = append(challenges, c)
+	}
+	return strings.Join(challenges, ", "), nil
+}
+
+func (e *DigestChallenge) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrDigestHeader    = errors.New("invalid digest authorization header")
+	ErrDigestAlgorithm = errors.New("unsupported digest algorithm")
+	ErrDigestQop       = errors.New("unsupported digest qop")
+	ErrDigestUri       = errors.New("digest uri does not match request")
+	ErrDigestResponse  = errors.New("invalid digest response")
+	ErrDigestHandler   = errors.New("digest: handler is not set")
+)
+
+// Init проверка настроек, вызывается при формировании маршрутов сервера
+func (d *Digest) Init() error {
+	if d.Handler == nil {
+		return ErrDigestHandler
+	}
+	d.store()
+	return nil
+}
+
+// Do без данных запроса возвращает только *DigestChallenge,
+// проверка выполняется в Verify
+func (d *Digest) Do() (*Identity, error) {
+	return nil, d.challenge(false, nil)
+}
+
+// Verify проверка заголовка Authorization запроса. При ошибке возвращается *DigestChallenge
+func (d *Digest) Verify(method, uri, header string, body []byte) (*Identity, error) {
+
+	if d.Handler == nil {
+		return nil, ErrDigestHandler
+	}
+
+	const prefix = "Digest "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return nil, d.challenge(false, nil)
+	}
+	params, ok := parseDigestHeader(header[len(prefix):])
+	if !ok {
+		return nil, d.challenge(false, ErrDigestHeader)
+	}
+
+	username := params["username"]
+	advanced := Advanced{
+		Realm:       params["realm"],
+		Nonce:       params["nonce"],
+		Uri:         params["uri"],
+		Algorithm:   params["algorithm"],
+		Qop:         params["qop"],
+		NonceCount:  params["nc"],
+		ClientNonce: params["cnonce"],
+		Opaque:      params["opaque"],
+	}
+	if advanced.Algorithm == "" {
+		advanced.Algorithm = Md5Algorithm
+	}
+	if username == "" || advanced.Nonce == "" || advanced.ClientNonce == "" || params["response"] == "" ||
+		advanced.Realm != d.realm() || advanced.Opaque != d.opaque() {
+		return nil, d.challenge(false, ErrDigestHeader)
+	}
+	if advanced.Uri != uri {
+		return nil, d.challenge(false, ErrDigestUri)
+	}
+	if !containsFold(d.algorithms(), advanced.Algorithm) {
+		return nil, d.challenge(false, ErrDigestAlgorithm)
+	}
+	if !contains(d.qop(), advanced.Qop) {
+		return nil, d.challenge(false, ErrDigestQop)
+	}
+	count, err := strconv.ParseUint(advanced.NonceCount, 16, 64)
+	if err != nil {
+		return nil, d.challenge(false, ErrDigestHeader)
+	}
+
+	password, ok := d.Handler(username, advanced)
+	if !ok {
+		return nil, d.challenge(false, ErrDigestResponse)
+	}
+	expected := digestResponse(username, password, method, body, advanced)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(params["response"]))) != 1 {
+		return nil, d.challenge(false, ErrDigestResponse)
+	}
+
+	// Счетчик учитываем только после проверки ответа, иначе его
+	// можно было бы сдвинуть без знания пароля
+	if err = d.store().Use(advanced.Nonce, count); err != nil {
+		return nil, d.challenge(errors.Is(err, ErrNonceExpired), err)
+	}
+
+	return &Identity{
+		Username: username,
+		AuthName: DigestAuth,
+	}, nil
+}
+
+func (d *Digest) Definition() Definition {
+	return Definition{
+		Type:        TypeBasic,
+		Scheme:      SchemeDigest,
+		Description: "Digest Authorization (RFC 7616)",
+	}
+}
+
+// challenge ошибка авторизации, nonce при этом не выдается
+func (d *Digest) challenge(stale bool, cause error) error {
+	return &DigestChallenge{
+		Stale:  stale,
+		Err:    cause,
+		digest: d,
+	}
+}
+
+func (d *Digest) realm() string {
+	if d.Realm == "" {
+		return DefaultDigestRealm
+	}
+	return d.Realm
+}
+
+// algorithms поддерживаемые алгоритмы из Algorithms, неизвестные не предлагаются клиенту и не принимаются
+func (d *Digest) algorithms() []string {
+	if len(d.Algorithms) == 0 {
+		return []string{Sha256Algorithm, Md5Algorithm}
+	}
+	var algorithms []string
+	for _, algorithm := range d.Algorithms {
+		if digestHash(algorithm) != nil {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	return algorithms
+}
+
+func (d *Digest) qop() []string {
+	if len(d.Qop) == 0 {
+		return []string{QopAuth}
+	}
+	return d.Qop
+}
+
+func (d *Digest) opaque() string {
+	if d.Opaque == "" {
+		sum := sha256.Sum256([]byte(d.realm()))
+		return hex.EncodeToString(sum[:16])
+	}
+	return d.Opaque
+}
+
+func (d *Digest) nonceExpires() time.Duration {
+	if d.NonceExpires == 0 {
+		return DefaultDigestNonceExpires
+	}
+	return d.NonceExpires
+}
+
+func (d *Digest) store() NonceStore {
+	d.once.Do(func() {
+		if d.Store == nil {
+			d.Store = NewMemoryNonceStore(DefaultNonceStoreSize)
+		}
+	})
+	return d.Store
+}
+
+// digestResponse расчет значения response по RFC 7616 (раздел 3.4.1)
+func digestResponse(username, password, method string, body []byte, a Advanced) string {
+
+	h := digestHash(a.Algorithm)
+	if h == nil {
+		return ""
+	}
+
+	ha1 := h(username + ":" + a.Realm + ":" + password)
+	if strings.HasSuffix(strings.ToUpper(a.Algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + a.Nonce + ":" + a.ClientNonce)
+	}
+	ha2 := h(method + ":" + a.Uri)
+	if a.Qop == QopAuthInt {
+		ha2 = h(method + ":" + a.Uri + ":" + h(string(body)))
+	}
+
+	return h(ha1 + ":" + a.Nonce + ":" + a.NonceCount + ":" + a.ClientNonce + ":" + a.Qop + ":" + ha2)
+}
+
+// digestHash хэш-функция алгоритма MD5, SHA-256 или SHA-512-256, в том числе с суффиксом -sess.
+// Для неизвестного алгоритма возвращается nil
+func digestHash(algorithm string) func(string) string {
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case strings.ToUpper(Md5Algorithm):
+		newHash = md5.New
+	case strings.ToUpper(Sha256Algorithm):
+		newHash = sha256.New
+	case strings.ToUpper(Sha512256Algorithm):
+		newHash = sha512.New512_256
+	default:
+		return nil
+	}
+	return func(s string) string {
+		h := newHash()
+		h.Write([]byte(s))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// parseDigestHeader разбор параметров заголовка вида key=value, key="quoted value"
+func parseDigestHeader(s string) (map[string]string, bool) {
+
+	params := map[string]string{}
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return params, true
+		}
+		i := strings.IndexByte(s, '=')
+		if i <= 0 {
+			return nil, false
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:i]))
+		s = strings.TrimLeft(s[i+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			j := 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, false
+			}
+			value, s = b.String(), s[j+1:]
+		} else {
+			j := strings.IndexByte(s, ',')
+			if j < 0 {
+				j = len(s)
+			}
+			value, s = strings.TrimSpace(s[:j]), s[j:]
+		}
+		params[key] = value
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
-func (Digest) Definition() Definition {
-	return Definition{}
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,218 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// digestClient ответ клиента на вызов сервера
+func digestClient(t *testing.T, challenge, algorithm, qop, password string, nc int, body []byte) string {
+
+	var params map[string]string
+	for _, c := range strings.Split(challenge, ", Digest ") {
+		p, ok := parseDigestHeader(strings.TrimPrefix(c, "Digest "))
+		if !ok {
+			t.Fatalf("invalid challenge %s", challenge)
+		}
+		if p["algorithm"] == algorithm {
+			params = p
+		}
+	}
+	if params == nil {
+		t.Fatalf("algorithm %s is not offered: %s", algorithm, challenge)
+	}
+
+	a := Advanced{
+		Realm:       params["realm"],
+		Nonce:       params["nonce"],
+		Uri:         "/api/pets?limit=10",
+		Algorithm:   algorithm,
+		Qop:         qop,
+		NonceCount:  fmt.Sprintf("%08x", nc),
+		ClientNonce: "0a4f113b",
+		Opaque:      params["opaque"],
+	}
+	return fmt.Sprintf(`Digest username="Mufasa", realm="%s", uri="%s", algorithm=%s, nonce="%s", nc=%s, cnonce="%s", qop=%s, response="%s", opaque="%s"`,
+		a.Realm, a.Uri, a.Algorithm, a.Nonce, a.NonceCount, a.ClientNonce, a.Qop,
+		digestResponse("Mufasa", password, "GET", body, a), a.Opaque)
+}
+
+func newTestDigest() *Digest {
+	return &Digest{
+		Realm: "http-auth@example.org",
+		Algorithms: []string{
+			Sha256Algorithm, Sha256SessAlgorithm,
+			Sha512256Algorithm, Sha512256SessAlgorithm,
+			Md5Algorithm, Md5SessAlgorithm,
+		},
+		Qop: []string{QopAuth, QopAuthInt},
+		Handler: func(user string, advanced Advanced) (string, bool) {
+			return "Circle of Life", user == "Mufasa"
+		},
+	}
+}
+
+func TestDigestResponse(t *testing.T) {
+	// Пример из RFC 7616 (раздел 3.9.1)
+	a := Advanced{
+		Realm:       "http-auth@example.org",
+		Nonce:       "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+		Uri:         "/dir/index.html",
+		Algorithm:   Sha256Algorithm,
+		Qop:         QopAuth,
+		NonceCount:  "00000001",
+		ClientNonce: "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+	}
+	expected := "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"
+	if r := digestResponse("Mufasa", "Circle of Life", "GET", nil, a); r != expected {
+		t.Errorf("SHA-256 response %s, want %s", r, expected)
+	}
+	a.Algorithm = Md5Algorithm
+	expected = "8ca523f5e9506fed4657c9700eebdbec"
+	if r := digestResponse("Mufasa", "Circle of Life", "GET", nil, a); r != expected {
+		t.Errorf("MD5 response %s, want %s", r, expected)
+	}
+}
+
+// issue вызов WWW-Authenticate с новым nonce из ошибки Digest
+func issue(t *testing.T, err error) string {
+	t.Helper()
+	var dc *DigestChallenge
+	if !errors.As(err, &dc) {
+		t.Fatalf("not a digest challenge: %v", err)
+	}
+	challenge, err := dc.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challenge
+}
+
+func TestDigest_Verify(t *testing.T) {
+
+	d := newTestDigest()
+	body := []byte(`{"name":"Simba"}`)
+
+	for _, algorithm := range d.Algorithms {
+		for _, qop := range d.Qop {
+			_, err := d.Do()
+			header := digestClient(t, issue(t, err), algorithm, qop, "Circle of Life", 1, body)
+			identity, err := d.Verify("GET", "/api/pets?limit=10", header, body)
+			if err != nil {
+				t.Errorf("%s %s: %v", algorithm, qop, errors.Unwrap(err))
+				continue
+			}
+			if identity.Username != "Mufasa" || identity.AuthName != DigestAuth {
+				t.Errorf("%s %s: identity %s", algorithm, qop, identity)
+			}
+			// Повтор запроса с тем же счетчиком
+			if _, err = d.Verify("GET", "/api/pets?limit=10", header, body); !errors.Is(err, ErrNonceReplay) {
+				t.Errorf("%s %s: replay error %v", algorithm, qop, errors.Unwrap(err))
+			}
+		}
+	}
+
+	_, err := d.Do()
+	challenge := issue(t, err)
+	header := digestClient(t, challenge, Sha256Algorithm, QopAuth, "Circle of Life", 2, nil)
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, nil); err != nil {
+		t.Errorf("nc 2: %v", errors.Unwrap(err))
+	}
+	header = digestClient(t, challenge, Sha256Algorithm, QopAuth, "Circle of Life", 5, nil)
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, nil); err != nil {
+		t.Errorf("nc 5: %v", errors.Unwrap(err))
+	}
+	header = digestClient(t, challenge, Sha256Algorithm, QopAuth, "Circle of Life", 3, nil)
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, nil); !errors.Is(err, ErrNonceReplay) {
+		t.Errorf("nc 3 after 5: %v", errors.Unwrap(err))
+	}
+
+	header = digestClient(t, challenge, Sha256Algorithm, QopAuth, "wrong", 6, nil)
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, nil); !errors.Is(err, ErrDigestResponse) {
+		t.Errorf("wrong password: %v", errors.Unwrap(err))
+	}
+	header = digestClient(t, challenge, Sha256Algorithm, QopAuth, "Circle of Life", 6, nil)
+	if _, err = d.Verify("GET", "/api/pets", header, nil); !errors.Is(err, ErrDigestUri) {
+		t.Errorf("other uri: %v", errors.Unwrap(err))
+	}
+	header = digestClient(t, challenge, Sha256Algorithm, QopAuthInt, "Circle of Life", 6, []byte("{}"))
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, []byte("[]")); !errors.Is(err, ErrDigestResponse) {
+		t.Errorf("other body: %v", errors.Unwrap(err))
+	}
+	if _, err = d.Verify("GET", "/api/pets?limit=10", "", nil); err == nil || errors.Unwrap(err) != nil {
+		t.Errorf("no header: %v", err)
+	}
+}
+
+func TestDigest_UnknownAlgorithm(t *testing.T) {
+
+	d := newTestDigest()
+	d.Algorithms = []string{"SHA-1", Md5Algorithm}
+
+	// Неизвестный алгоритм не предлагается клиенту
+	_, err := d.Do()
+	challenge := issue(t, err)
+	if strings.Contains(challenge, "SHA-1") {
+		t.Errorf("challenge %s", challenge)
+	}
+	// и не проверяется как MD5
+	header := digestClient(t, challenge, Md5Algorithm, QopAuth, "Circle of Life", 1, nil)
+	header = strings.Replace(header, "algorithm=MD5", "algorithm=SHA-1", 1)
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, nil); !errors.Is(err, ErrDigestAlgorithm) {
+		t.Errorf("unknown algorithm: %v", errors.Unwrap(err))
+	}
+}
+
+func TestDigest_Stale(t *testing.T) {
+
+	d := newTestDigest()
+	d.NonceExpires = time.Millisecond
+
+	_, err := d.Do()
+	header := digestClient(t, issue(t, err), Md5Algorithm, QopAuth, "Circle of Life", 1, nil)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = d.Verify("GET", "/api/pets?limit=10", header, nil)
+	if !errors.Is(err, ErrNonceExpired) {
+		t.Fatalf("expired nonce: %v", err)
+	}
+	if !strings.Contains(issue(t, err), "stale=true") {
+		t.Errorf("challenge without stale: %s", err)
+	}
+}
+
+func TestDigest_Nonces(t *testing.T) {
+
+	d := newTestDigest()
+	store := NewMemoryNonceStore(3)
+	d.Store = store
+
+	// Ошибка авторизации сама по себе nonce не выдает
+	_, err := d.Verify("GET", "/", "", nil)
+	if err == nil || store.Len() != 0 {
+		t.Fatalf("nonces without challenge: %d", store.Len())
+	}
+
+	// Размер хранилища ограничен, самые старые значения вытесняются
+	first := issue(t, err)
+	for i := 0; i < 5; i++ {
+		issue(t, err)
+	}
+	if store.Len() != 3 {
+		t.Errorf("nonces %d, want 3", store.Len())
+	}
+	header := digestClient(t, first, Sha256Algorithm, QopAuth, "Circle of Life", 1, nil)
+	if _, err = d.Verify("GET", "/api/pets?limit=10", header, nil); !errors.Is(err, ErrNonceUnknown) {
+		t.Errorf("evicted nonce: %v", err)
+	}
+
+	if err = (&Digest{}).Init(); !errors.Is(err, ErrDigestHandler) {
+		t.Errorf("init without handler: %v", err)
+	}
+	if _, err = (&Digest{}).Verify("GET", "/", "", nil); !errors.Is(err, ErrDigestHandler) {
+		t.Errorf("verify without handler: %v", err)
+	}
+}
@@ -6,6 +6,43 @@ import "fmt"
 type Identity struct {
 	Username string
 	AuthName string
+	// Subject идентификатор субъекта токена
+	Subject string
+	// Roles роли пользователя
+	Roles []string
+	// Scopes области доступа токена
+	Scopes []string
+	// Claims произвольные утверждения схемы авторизации или сессии
+	Claims Claims
+}
+
+// IdentifyHandler дополнение идентификации ролями, областями доступа и утверждениями
+// после успешной авторизации или проверки сессии
+type IdentifyHandler func(identity *Identity) error
+
+// HasRole наличие хотя бы одной из ролей
+func (i Identity) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if contains(i.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScopes наличие всех областей доступа
+func (i Identity) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !contains(i.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Claim значение утверждения, nil если не задано
+func (i Identity) Claim(name string) interface{} {
+	return i.Claims[name]
 }
 
 func (i Identity) String() string {
@@ -0,0 +1,83 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Introspection клиент проверки токена на сервере авторизации (RFC 7662).
+// Сервер ресурсов аутентифицируется по ClientId и ClientSecret через Basic.
+// Сетевые ошибки и коды 5xx возвращаются с ErrValidatorUnavailable, прочие
+// неожиданные ответы с ErrValidatorFailed
+type Introspection struct {
+	URL          string
+	ClientId     string
+	ClientSecret string
+	// Client по умолчанию http.Client с тайм-аутом 10 секунд
+	Client *http.Client
+}
+
+type introspectionResponse struct {
+	Active   bool   `json:"active"`
+	Scope    string `json:"scope"`
+	ClientId string `json:"client_id"`
+	Username string `json:"username"`
+	Subject  string `json:"sub"`
+	Expires  int64  `json:"exp"`
+}
+
+func (i *Introspection) Validate(token string) (*Token, error) {
+
+	form := url.Values{
+		"token":           {token},
+		"token_type_hint": {"access_token"},
+	}
+	req, err := http.NewRequest(http.MethodPost, i.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidatorFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.ClientId != "" {
+		req.SetBasicAuth(url.QueryEscape(i.ClientId), url.QueryEscape(i.ClientSecret))
+	}
+
+	client := i.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidatorUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: introspection status %d", ErrValidatorUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: introspection status %d", ErrValidatorFailed, resp.StatusCode)
+	}
+	r := introspectionResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("%w: introspection: %s", ErrValidatorFailed, err)
+	}
+	if !r.Active {
+		return nil, ErrTokenInactive
+	}
+
+	t := &Token{
+		Subject:  r.Subject,
+		Username: r.Username,
+		ClientId: r.ClientId,
+		Scopes:   strings.Fields(r.Scope),
+	}
+	if r.Expires > 0 {
+		t.Expires = time.Unix(r.Expires, 0)
+	}
+	return t, nil
+}
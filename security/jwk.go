@@ -0,0 +1,278 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval минимальный интервал проверки файла JWKS по умолчанию
+const DefaultReloadInterval = 10 * time.Second
+
+var ErrJWTKeyNotFound = errors.New("jwt: key not found")
+
+// KeySet набор ключей проверки подписи по идентификатору kid. Ключи HMAC
+// хранятся как []byte, асимметричные - как открытые ключи
+type KeySet struct {
+	// ReloadInterval минимальный интервал между проверками файла JWKS при запросе
+	// неизвестного kid, по умолчанию DefaultReloadInterval
+	ReloadInterval time.Duration
+
+	mu      sync.RWMutex
+	keys    map[string]interface{}
+	path    string
+	modTime time.Time
+	checked time.Time
+}
+
+// NewKeySet Инициализация пустого набора ключей
+func NewKeySet() *KeySet {
+	return &KeySet{
+		keys: map[string]interface{}{},
+	}
+}
+
+// NewJWKSFile Инициализация набора ключей из файла JWKS (RFC 7517). При запросе
+// неизвестного kid файл перечитывается, если он был изменен, что позволяет менять ключи без перезапуска.
+// Файл проверяется не чаще одного раза за ReloadInterval
+func NewJWKSFile(path string) (*KeySet, error) {
+	k := NewKeySet()
+	k.path = path
+	if err := k.reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Add добавить ключ. Закрытые ключи заменяются открытыми
+func (k *KeySet) Add(kid string, key interface{}) *KeySet {
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+	k.mu.Lock()
+	k.keys[kid] = key
+	k.mu.Unlock()
+	return k
+}
+
+// AddPEM добавить ключ в формате PEM
+func (k *KeySet) AddPEM(kid string, b []byte) error {
+	key, err := ParsePEM(b)
+	if err != nil {
+		return err
+	}
+	k.Add(kid, key)
+	return nil
+}
+
+// AddJWKS добавить ключи из документа JWKS
+func (k *KeySet) AddJWKS(b []byte) error {
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	for kid, key := range keys {
+		k.keys[kid] = key
+	}
+	k.mu.Unlock()
+	return nil
+}
+
+// Get вернуть ключ по kid. Если ключ единственный, то он возвращается и для пустого kid
+func (k *KeySet) Get(kid string) (interface{}, error) {
+
+	if key, ok := k.get(kid); ok {
+		return key, nil
+	}
+	if k.path != "" && k.check() {
+		info, err := os.Stat(k.path)
+		if err == nil && !info.ModTime().Equal(k.modified()) {
+			if err = k.reload(); err != nil {
+				return nil, err
+			}
+			if key, ok := k.get(kid); ok {
+				return key, nil
+			}
+		}
+	}
+	return nil, ErrJWTKeyNotFound
+}
+
+func (k *KeySet) get(kid string) (interface{}, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	if !ok && kid == "" && len(k.keys) == 1 {
+		for _, key = range k.keys {
+			ok = true
+		}
+	}
+	return key, ok
+}
+
+// check разрешение проверки файла, не чаще одного раза за ReloadInterval
+func (k *KeySet) check() bool {
+	interval := k.ReloadInterval
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	if now.Sub(k.checked) < interval {
+		return false
+	}
+	k.checked = now
+	return true
+}
+
+func (k *KeySet) modified() time.Time {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.modTime
+}
+
+// reload перечитать файл JWKS, ключи заменяются полностью
+func (k *KeySet) reload() error {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return fmt.Errorf("%s: %s", k.path, err)
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.modTime = info.ModTime()
+	k.mu.Unlock()
+	return nil
+}
+
+// ParsePEM разбор ключа PEM: открытый ключ, сертификат или закрытый ключ
+// в форматах PKCS#1, PKCS#8 и SEC 1
+func ParsePEM(b []byte) (interface{}, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("jwt: invalid PEM")
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("jwt: unsupported PEM block %s", block.Type)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS разбор документа JWKS. Ключи шифрования (use = enc) пропускаются
+func parseJWKS(b []byte) (map[string]interface{}, error) {
+
+	doc := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, j := range doc.Keys {
+		if j.Use == "enc" {
+			continue
+		}
+		key, err := j.key()
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: %s", j.Kid, err)
+		}
+		keys[j.Kid] = key
+	}
+	return keys, nil
+}
+
+func (j jwk) key() (interface{}, error) {
+
+	decode := func(s string) *big.Int {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return new(big.Int).SetBytes(b)
+	}
+
+	switch j.Kty {
+	case "RSA":
+		if j.N == "" || j.E == "" {
+			return nil, errors.New("missing n or e")
+		}
+		return &rsa.PublicKey{N: decode(j.N), E: int(decode(j.E).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: decode(j.X), Y: decode(j.Y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return key, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if j.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := base64.RawURLEncoding.DecodeString(j.K)
+		if err != nil || len(k) == 0 {
+			return nil, errors.New("invalid symmetric key")
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
@@ -0,0 +1,418 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Алгоритмы подписи JWT (RFC 7518, RFC 8037)
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+	EdDSA = "EdDSA"
+)
+
+var (
+	ErrJWTMalformed    = errors.New("jwt: malformed token")
+	ErrJWTAlgorithm    = errors.New("jwt: unsupported algorithm")
+	ErrJWTSignature    = errors.New("jwt: invalid signature")
+	ErrJWTExpired      = errors.New("jwt: token expired")
+	ErrJWTNotYetValid  = errors.New("jwt: token is not valid yet")
+	ErrJWTIssuer       = errors.New("jwt: invalid issuer")
+	ErrJWTAudience     = errors.New("jwt: invalid audience")
+	ErrJWTSigningKey   = errors.New("jwt: signing key is not set")
+	ErrJWTKeyAlgorithm = errors.New("jwt: key does not match algorithm")
+	ErrJWTClaimType    = errors.New("jwt: invalid claim type")
+)
+
+// JWT авторизация по токену JWT в заголовке "Authorization: Bearer <token>".
+// Подпись проверяется ключами из Keys по kid заголовка токена, для HMAC можно указать Secret
+type JWT struct {
+	Realm string
+	// Algorithms допустимые алгоритмы, по умолчанию все поддерживаемые
+	Algorithms []string
+	Secret     []byte
+	Keys       *KeySet
+	// Issuer и Audience если указаны, то проверяются утверждения iss и aud
+	Issuer   string
+	Audience string
+	// Leeway допустимое расхождение часов при проверке exp и nbf
+	Leeway time.Duration
+
+	// Параметры выпуска токенов в Issue. По умолчанию HS256 с ключом Secret
+	SigningMethod string
+	SigningKey    interface{}
+	SigningKid    string
+	// Expires срок действия выпускаемых токенов, 0 - без ограничения
+	Expires time.Duration
+}
+
+// Claims утверждения токена
+type Claims map[string]interface{}
+
+// Subject утверждение sub
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Audience утверждение aud, строка или массив строк
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		var list []string
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	case []string:
+		return aud
+	}
+	return nil
+}
+
+// Time утверждение со временем в секундах (exp, nbf, iat). Возвращает false,
+// если утверждение отсутствует или не является числом
+func (c Claims) Time(name string) (time.Time, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return time.Unix(n, 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Do без данных запроса возвращает только вызов WWW-Authenticate,
+// проверка выполняется в Verify
+func (j *JWT) Do() (*Identity, error) {
+	return nil, bearerChallenge(j.Realm, ErrTokenMissing, nil)
+}
+
+// Verify проверка токена из заголовка Authorization
+func (j *JWT) Verify(header string) (*Identity, error) {
+
+	token, ok := bearerToken(header)
+	if !ok {
+		return nil, bearerChallenge(j.Realm, ErrTokenMissing, nil)
+	}
+	claims, err := j.Parse(token)
+	if err != nil {
+		return nil, bearerChallenge(j.Realm, err, nil)
+	}
+
+	identity := &Identity{
+		Username: claims.Subject(),
+		AuthName: JWTAuth,
+		Subject:  claims.Subject(),
+		Claims:   claims,
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		identity.Scopes = strings.Fields(scope)
+	}
+	return identity, nil
+}
+
+// Parse проверка подписи и утверждений токена
+func (j *JWT) Parse(token string) (Claims, error) {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+	h := jwtHeader{}
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if !j.allowed(h.Alg) {
+		return nil, ErrJWTAlgorithm
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+
+	key, err := j.verificationKey(h)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifySignature(h.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	claims := Claims{}
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if err = j.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Issue выпуск токена с утверждениями claims. Если не заданы, то заполняются
+// iat, exp (при Expires > 0), iss и aud
+func (j *JWT) Issue(claims Claims) (string, error) {
+
+	now := time.Now()
+	c := Claims{"iat": now.Unix()}
+	if j.Expires > 0 {
+		c["exp"] = now.Add(j.Expires).Unix()
+	}
+	if j.Issuer != "" {
+		c["iss"] = j.Issuer
+	}
+	if j.Audience != "" {
+		c["aud"] = j.Audience
+	}
+	for name, value := range claims {
+		c[name] = value
+	}
+
+	method, key := j.SigningMethod, j.SigningKey
+	if key == nil && j.Secret != nil {
+		key = j.Secret
+	}
+	if method == "" {
+		method = HS256
+	}
+	if key == nil {
+		return "", ErrJWTSigningKey
+	}
+	return SignJWT(method, j.SigningKid, key, c)
+}
+
+func (j *JWT) Definition() Definition {
+	return Definition{
+		Type:         TypeApiKey,
+		Name:         "Authorization",
+		In:           ParamHeader,
+		Description:  "JWT Authorization. Set header: Bearer {token}",
+		BearerFormat: "JWT",
+	}
+}
+
+// allowed проверка алгоритма по списку допустимых
+func (j *JWT) allowed(alg string) bool {
+	if _, ok := jwtHashes[alg]; !ok && alg != EdDSA {
+		return false
+	}
+	return len(j.Algorithms) == 0 || contains(j.Algorithms, alg)
+}
+
+// verificationKey ключ по kid, для HMAC без ключа в наборе - Secret
+func (j *JWT) verificationKey(h jwtHeader) (interface{}, error) {
+	if j.Keys != nil {
+		key, err := j.Keys.Get(h.Kid)
+		if err == nil {
+			return key, nil
+		}
+		if !strings.HasPrefix(h.Alg, "HS") || j.Secret == nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(h.Alg, "HS") && j.Secret != nil {
+		return j.Secret, nil
+	}
+	return nil, ErrJWTKeyNotFound
+}
+
+// validate проверка exp, nbf, iss и aud. Токен с exp или nbf не числового типа отклоняется
+func (j *JWT) validate(c Claims) error {
+	for _, name := range []string{"exp", "nbf"} {
+		if _, ok := c[name]; !ok {
+			continue
+		}
+		if _, ok := c.Time(name); !ok {
+			return ErrJWTClaimType
+		}
+	}
+	now := time.Now()
+	if exp, ok := c.Time("exp"); ok && now.After(exp.Add(j.Leeway)) {
+		return ErrJWTExpired
+	}
+	if nbf, ok := c.Time("nbf"); ok && now.Add(j.Leeway).Before(nbf) {
+		return ErrJWTNotYetValid
+	}
+	if j.Issuer != "" {
+		if iss, _ := c["iss"].(string); iss != j.Issuer {
+			return ErrJWTIssuer
+		}
+	}
+	if j.Audience != "" && !contains(c.Audience(), j.Audience) {
+		return ErrJWTAudience
+	}
+	return nil
+}
+
+// SignJWT подпись утверждений claims алгоритмом alg. Ключ: []byte для HMAC,
+// *rsa.PrivateKey, *ecdsa.PrivateKey или ed25519.PrivateKey
+func SignJWT(alg, kid string, key interface{}, claims Claims) (string, error) {
+
+	header, err := json.Marshal(jwtHeader{Alg: alg, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	hash, ok := jwtHashes[alg]
+	switch k := key.(type) {
+	case []byte:
+		if !ok || !strings.HasPrefix(alg, "HS") {
+			return "", ErrJWTKeyAlgorithm
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		if !ok || !strings.HasPrefix(alg, "RS") {
+			return "", ErrJWTKeyAlgorithm
+		}
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest(hash, input))
+	case *ecdsa.PrivateKey:
+		size, okSize := ecdsaSizes[alg]
+		if !ok || !okSize || k.Curve.Params().BitSize != size {
+			return "", ErrJWTKeyAlgorithm
+		}
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, k, digest(hash, input)); err == nil {
+			n := (size + 7) / 8
+			signature = make([]byte, 2*n)
+			r.FillBytes(signature[:n])
+			s.FillBytes(signature[n:])
+		}
+	case ed25519.PrivateKey:
+		if alg != EdDSA {
+			return "", ErrJWTKeyAlgorithm
+		}
+		signature = ed25519.Sign(k, []byte(input))
+	default:
+		return "", ErrJWTKeyAlgorithm
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+var jwtHashes = map[string]crypto.Hash{
+	HS256: crypto.SHA256,
+	HS384: crypto.SHA384,
+	HS512: crypto.SHA512,
+	RS256: crypto.SHA256,
+	RS384: crypto.SHA384,
+	RS512: crypto.SHA512,
+	ES256: crypto.SHA256,
+	ES384: crypto.SHA384,
+	ES512: crypto.SHA512,
+}
+
+// ecdsaSizes размер кривой для алгоритмов ES*
+var ecdsaSizes = map[string]int{
+	ES256: 256,
+	ES384: 384,
+	ES512: 521,
+}
+
+// verifySignature проверка подписи. Тип ключа должен соответствовать алгоритму,
+// что исключает подмену алгоритма (например HS256 с открытым ключом RSA)
+func verifySignature(alg string, key interface{}, input, signature []byte) error {
+
+	hash := jwtHashes[alg]
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(alg, "HS") {
+			return ErrJWTKeyAlgorithm
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write(input)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrJWTSignature
+		}
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return ErrJWTKeyAlgorithm
+		}
+		if rsa.VerifyPKCS1v15(k, hash, digest(hash, string(input)), signature) != nil {
+			return ErrJWTSignature
+		}
+	case *ecdsa.PublicKey:
+		size, ok := ecdsaSizes[alg]
+		if !ok || k.Curve.Params().BitSize != size {
+			return ErrJWTKeyAlgorithm
+		}
+		n := (size + 7) / 8
+		if len(signature) != 2*n {
+			return ErrJWTSignature
+		}
+		r := new(big.Int).SetBytes(signature[:n])
+		s := new(big.Int).SetBytes(signature[n:])
+		if !ecdsa.Verify(k, digest(hash, string(input)), r, s) {
+			return ErrJWTSignature
+		}
+	case ed25519.PublicKey:
+		if alg != EdDSA {
+			return ErrJWTKeyAlgorithm
+		}
+		if !ed25519.Verify(k, input, signature) {
+			return ErrJWTSignature
+		}
+	default:
+		return fmt.Errorf("jwt: unsupported key type %T", key)
+	}
+	return nil
+}
+
+func digest(hash crypto.Hash, input string) []byte {
+	h := hash.New()
+	h.Write([]byte(input))
+	return h.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,181 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// publicJWK открытый ключ в формате JWK
+func publicJWK(kid string, key interface{}) map[string]string {
+	enc := base64.RawURLEncoding.EncodeToString
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return map[string]string{"kty": "RSA", "kid": kid, "n": enc(k.N.Bytes()), "e": enc(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PrivateKey:
+		return map[string]string{"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "x": enc(k.X.Bytes()), "y": enc(k.Y.Bytes())}
+	case ed25519.PrivateKey:
+		return map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": enc(k.Public().(ed25519.PublicKey))}
+	case []byte:
+		return map[string]string{"kty": "oct", "kid": kid, "k": enc(k)}
+	}
+	return nil
+}
+
+func writeJWKS(t *testing.T, path string, keys map[string]interface{}) {
+	doc := map[string][]map[string]string{"keys": {}}
+	for kid, key := range keys {
+		doc["keys"] = append(doc["keys"], publicJWK(kid, key))
+	}
+	b, _ := json.Marshal(doc)
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWT_Algorithms(t *testing.T) {
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	keys := map[string]interface{}{
+		HS256: secret, HS384: secret, HS512: secret,
+		RS256: rsaKey, RS384: rsaKey, RS512: rsaKey,
+		ES256: p256, ES384: p384, ES512: p521,
+		EdDSA: edKey,
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, keys)
+	set, err := NewJWKSFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := &JWT{Keys: set, Issuer: "ewa", Audience: "api"}
+	for alg, key := range keys {
+		token, err := SignJWT(alg, alg, key, Claims{"sub": "user", "iss": "ewa", "aud": []string{"web", "api"}, "scope": "a b"})
+		if err != nil {
+			t.Errorf("%s: %s", alg, err)
+			continue
+		}
+		identity, err := j.Verify("Bearer " + token)
+		if err != nil {
+			t.Errorf("%s: %v", alg, errors.Unwrap(err))
+			continue
+		}
+		if identity.Username != "user" || identity.AuthName != JWTAuth || len(identity.Scopes) != 2 || identity.Claims["iss"] != "ewa" {
+			t.Errorf("%s: identity %+v", alg, identity)
+		}
+	}
+
+	// Подмена алгоритма: HMAC с ключом RSA из набора
+	token, _ := SignJWT(HS256, RS256, secret, Claims{"sub": "user"})
+	if _, err = j.Parse(token); !errors.Is(err, ErrJWTKeyAlgorithm) {
+		t.Errorf("algorithm confusion: %v", err)
+	}
+	token, _ = SignJWT(HS256, HS256, []byte("other"), Claims{"sub": "user", "iss": "ewa", "aud": "api"})
+	if _, err = j.Parse(token); !errors.Is(err, ErrJWTSignature) {
+		t.Errorf("wrong secret: %v", err)
+	}
+	if _, err = j.Parse("eyJhbGciOiJub25lIn0.eyJzdWIiOiJ1c2VyIn0."); !errors.Is(err, ErrJWTAlgorithm) {
+		t.Errorf("alg none: %v", err)
+	}
+	j.Algorithms = []string{RS256}
+	token, _ = SignJWT(ES256, ES256, p256, Claims{"sub": "user", "iss": "ewa", "aud": "api"})
+	if _, err = j.Parse(token); !errors.Is(err, ErrJWTAlgorithm) {
+		t.Errorf("not allowed algorithm: %v", err)
+	}
+
+	// Ротация ключей: новый kid появляется после изменения файла
+	rotated, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	token, _ = SignJWT(ES256, "2024", rotated, Claims{"sub": "user", "iss": "ewa", "aud": "api"})
+	j.Algorithms = nil
+	if _, err = j.Parse(token); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("unknown kid: %v", err)
+	}
+	writeJWKS(t, path, map[string]interface{}{"2024": rotated})
+	later := time.Now().Add(time.Second)
+	_ = os.Chtimes(path, later, later)
+	// Повторная проверка файла раньше ReloadInterval не выполняется
+	if _, err = j.Parse(token); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("reload before interval: %v", err)
+	}
+	set.ReloadInterval = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	if _, err = j.Parse(token); err != nil {
+		t.Errorf("rotated kid: %v", err)
+	}
+}
+
+func TestJWT_Claims(t *testing.T) {
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	set := NewKeySet()
+	if err := set.AddPEM("", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})); err != nil {
+		t.Fatal(err)
+	}
+
+	j := &JWT{
+		Keys:          set,
+		Issuer:        "ewa",
+		Audience:      "api",
+		Leeway:        time.Minute,
+		SigningMethod: ES256,
+		SigningKey:    key,
+		Expires:       time.Hour,
+	}
+	token, err := j.Issue(Claims{"sub": "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := j.Parse(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject() != "user" || claims.Audience()[0] != "api" {
+		t.Errorf("claims %v", claims)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		claims Claims
+		err    error
+	}{
+		{Claims{"exp": now.Add(-30 * time.Second).Unix()}, nil},
+		{Claims{"exp": now.Add(-2 * time.Minute).Unix()}, ErrJWTExpired},
+		{Claims{"nbf": now.Add(30 * time.Second).Unix()}, nil},
+		{Claims{"nbf": now.Add(2 * time.Minute).Unix()}, ErrJWTNotYetValid},
+		{Claims{"iss": "other"}, ErrJWTIssuer},
+		{Claims{"aud": []string{"web"}}, ErrJWTAudience},
+		{Claims{"exp": "never"}, ErrJWTClaimType},
+		{Claims{"nbf": true}, ErrJWTClaimType},
+	}
+	for _, test := range tests {
+		token, _ = j.Issue(test.claims)
+		if _, err = j.Parse(token); !errors.Is(err, test.err) {
+			t.Errorf("%v: error %v, want %v", test.claims, err, test.err)
+		}
+	}
+
+	if _, err = (&JWT{}).Issue(Claims{}); !errors.Is(err, ErrJWTSigningKey) {
+		t.Errorf("issue without key: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNonceUnknown = errors.New("unknown nonce")
+	ErrNonceExpired = errors.New("nonce expired")
+	ErrNonceReplay  = errors.New("nonce count reused")
+)
+
+// NonceStore хранилище выданных значений nonce. Хранилище отвечает за срок действия
+// nonce и за защиту от повтора: счетчик запросов nc для каждого nonce должен возрастать
+type NonceStore interface {
+	// Add сохранить новый nonce со сроком действия
+	Add(nonce string, expires time.Time) error
+	// Use проверить nonce и счетчик запросов. Возвращает ErrNonceUnknown,
+	// ErrNonceExpired или ErrNonceReplay
+	Use(nonce string, count uint64) error
+}
+
+// DefaultNonceStoreSize количество nonce в хранилище в памяти по умолчанию
+const DefaultNonceStoreSize = 10000
+
+type nonceEntry struct {
+	expires time.Time
+	count   uint64
+}
+
+// MemoryNonceStore хранилище nonce в памяти процесса ограниченного размера.
+// Значения хранятся в кольцевом буфере: новый nonce вытесняет самый старый,
+// поэтому размер хранилища не зависит от количества запросов без авторизации
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]*nonceEntry
+	ring   []string
+	next   int
+}
+
+// NewMemoryNonceStore Инициализация хранилища nonce в памяти на size значений,
+// при size <= 0 используется DefaultNonceStoreSize
+func NewMemoryNonceStore(size int) *MemoryNonceStore {
+	if size <= 0 {
+		size = DefaultNonceStoreSize
+	}
+	return &MemoryNonceStore{
+		nonces: make(map[string]*nonceEntry, size),
+		ring:   make([]string, size),
+	}
+}
+
+func (s *MemoryNonceStore) Add(nonce string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Вытесняем самое старое значение
+	if old := s.ring[s.next]; old != "" {
+		delete(s.nonces, old)
+	}
+	s.ring[s.next] = nonce
+	s.next = (s.next + 1) % len(s.ring)
+	s.nonces[nonce] = &nonceEntry{expires: expires}
+	return nil
+}
+
+// Len количество сохраненных nonce
+func (s *MemoryNonceStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.nonces)
+}
+
+func (s *MemoryNonceStore) Use(nonce string, count uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.nonces[nonce]
+	if !ok {
+		return ErrNonceUnknown
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.nonces, nonce)
+		return ErrNonceExpired
+	}
+	if count <= entry.count {
+		return ErrNonceReplay
+	}
+	entry.count = count
+	return nil
+}
+
+// newNonce случайное значение в шестнадцатеричном виде
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
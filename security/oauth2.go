@@ -1,12 +1,185 @@
 package security
 
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Варианты потоков OAuth2 в терминах swagger 2.0
+const (
+	FlowImplicit    = "implicit"
+	FlowPassword    = "password"
+	FlowApplication = "application"
+	FlowAccessCode  = "accessCode"
+)
+
+var (
+	ErrTokenMissing      = errors.New("bearer token is missing")
+	ErrTokenInactive     = errors.New("token is not active")
+	ErrTokenExpired      = errors.New("token expired")
+	ErrInsufficientScope = errors.New("insufficient scope")
+	// ErrValidatorUnavailable сервер проверки токена недоступен или вернул код 5xx
+	ErrValidatorUnavailable = errors.New("token validator is unavailable")
+	// ErrValidatorFailed сервер проверки токена вернул неожиданный ответ
+	ErrValidatorFailed = errors.New("token validator failed")
+)
+
+// OAuth2 авторизация сервера ресурсов по токену Bearer (RFC 6750).
+// Токен проверяется через Validator, например Introspection (RFC 7662)
 type OAuth2 struct {
+	Realm            string
+	Flow             string
+	AuthorizationURL string
+	TokenURL         string
+	// Scopes описание областей доступа для swagger
+	Scopes    map[string]string
+	Validator TokenValidator
+}
+
+// Token сведения о проверенном токене
+type Token struct {
+	Subject  string
+	Username string
+	ClientId string
+	Scopes   []string
+	// Expires время окончания действия, нулевое значение - без ограничения
+	Expires time.Time
+}
+
+// TokenValidator проверка токена доступа
+type TokenValidator interface {
+	Validate(token string) (*Token, error)
+}
+
+// TokenValidatorFunc функция проверки токена как TokenValidator
+type TokenValidatorFunc func(token string) (*Token, error)
+
+func (f TokenValidatorFunc) Validate(token string) (*Token, error) {
+	return f(token)
+}
+
+// BearerChallenge ошибка авторизации, текст ошибки - значение заголовка WWW-Authenticate
+type BearerChallenge struct {
+	Challenge string
+	Err       error
+}
+
+func (e *BearerChallenge) Error() string {
+	return e.Challenge
+}
+
+func (e *BearerChallenge) Unwrap() error {
+	return e.Err
+}
+
+// Do без данных запроса возвращает только вызов WWW-Authenticate,
+// проверка выполняется в Verify
+func (o *OAuth2) Do() (*Identity, error) {
+	return nil, bearerChallenge(o.Realm, ErrTokenMissing, nil)
+}
+
+// Verify проверка заголовка Authorization и наличия у токена всех областей доступа scopes.
+// При нехватке областей возвращается ошибка с ErrInsufficientScope. Ошибки
+// ErrValidatorUnavailable и ErrValidatorFailed возвращаются без вызова Bearer,
+// так как токен при этом не проверен
+func (o *OAuth2) Verify(header string, scopes []string) (*Identity, error) {
+
+	value, ok := bearerToken(header)
+	if !ok {
+		return nil, bearerChallenge(o.Realm, ErrTokenMissing, nil)
+	}
+	if o.Validator == nil {
+		return nil, bearerChallenge(o.Realm, ErrTokenInactive, nil)
+	}
+
+	token, err := o.Validator.Validate(value)
+	if err == nil && token == nil {
+		err = ErrTokenInactive
+	}
+	if err == nil && !token.Expires.IsZero() && time.Now().After(token.Expires) {
+		err = ErrTokenExpired
+	}
+	if errors.Is(err, ErrValidatorUnavailable) || errors.Is(err, ErrValidatorFailed) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, bearerChallenge(o.Realm, err, nil)
+	}
+
+	for _, scope := range scopes {
+		if !contains(token.Scopes, scope) {
+			return nil, bearerChallenge(o.Realm, ErrInsufficientScope, scopes)
+		}
+	}
+
+	username := token.Username
+	if username == "" {
+		username = token.Subject
+	}
+	return &Identity{
+		Username: username,
+		AuthName: OAuth2Auth,
+		Subject:  token.Subject,
+		Scopes:   token.Scopes,
+	}, nil
+}
+
+func (o *OAuth2) Definition() Definition {
+	return Definition{
+		Type:             TypeOAuth2,
+		Description:      "OAuth2 Authorization",
+		Flow:             o.Flow,
+		AuthorizationURL: o.AuthorizationURL,
+		TokenURL:         o.TokenURL,
+		Scopes:           o.Scopes,
+	}
+}
+
+// bearerChallenge вызов Bearer с кодом ошибки по RFC 6750 (раздел 3.1).
+// При отсутствии токена код ошибки не указывается. Текст ошибки проверки
+// в заголовок не попадает, описание выбирается из известных причин
+func bearerChallenge(realm string, err error, scopes []string) error {
+
+	var params []string
+	if realm != "" {
+		params = append(params, `realm="`+realm+`"`)
+	}
+	switch {
+	case errors.Is(err, ErrTokenMissing):
+	case errors.Is(err, ErrInsufficientScope):
+		params = append(params, `error="insufficient_scope"`, `scope="`+strings.Join(scopes, " ")+`"`)
+	default:
+		params = append(params, `error="invalid_token"`, `error_description="`+tokenErrorDescription(err)+`"`)
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	return &BearerChallenge{
+		Challenge: challenge,
+		Err:       err,
+	}
 }
 
-func (OAuth2) Do() (identity *Identity, err error) {
-	return
+// tokenErrorDescription описание ошибки недействительного токена для error_description
+func tokenErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return ErrTokenExpired.Error()
+	case errors.Is(err, ErrTokenInactive):
+		return ErrTokenInactive.Error()
+	}
+	return "token is invalid"
 }
 
-func (OAuth2) Definition() Definition {
-	return Definition{}
+// bearerToken токен из значения заголовка Authorization вида "Bearer <token>"
+func bearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
 }
@@ -0,0 +1,125 @@
+package security
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newIntrospectionServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "api" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		resp := map[string]interface{}{"active": false}
+		switch r.PostForm.Get("token") {
+		case "reader":
+			resp = map[string]interface{}{"active": true, "sub": "42", "scope": "pets:read", "client_id": "web"}
+		case "writer":
+			resp = map[string]interface{}{"active": true, "sub": "7", "username": "admin", "scope": "pets:read pets:write"}
+		case "expired":
+			resp = map[string]interface{}{"active": true, "sub": "42", "exp": time.Now().Add(-time.Minute).Unix()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestOAuth2_Verify(t *testing.T) {
+
+	server := newIntrospectionServer(t)
+	defer server.Close()
+
+	o := &OAuth2{
+		Realm: "pets",
+		Validator: &Introspection{
+			URL:          server.URL,
+			ClientId:     "api",
+			ClientSecret: "secret",
+		},
+	}
+
+	identity, err := o.Verify("Bearer reader", []string{"pets:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "42" || identity.Subject != "42" || identity.AuthName != OAuth2Auth ||
+		strings.Join(identity.Scopes, " ") != "pets:read" {
+		t.Errorf("identity %+v", identity)
+	}
+
+	identity, err = o.Verify("Bearer writer", []string{"pets:read", "pets:write"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "admin" || identity.Subject != "7" {
+		t.Errorf("identity %+v", identity)
+	}
+
+	tests := []struct {
+		header    string
+		scopes    []string
+		err       error
+		challenge string
+	}{
+		{"", nil, ErrTokenMissing, `Bearer realm="pets"`},
+		{"Basic dXNlcjpwYXNz", nil, ErrTokenMissing, `Bearer realm="pets"`},
+		{"Bearer unknown", nil, ErrTokenInactive, `Bearer realm="pets", error="invalid_token", error_description="token is not active"`},
+		{"Bearer expired", nil, ErrTokenExpired, `Bearer realm="pets", error="invalid_token", error_description="token expired"`},
+		{"Bearer reader", []string{"pets:read", "pets:write"}, ErrInsufficientScope, `Bearer realm="pets", error="insufficient_scope", scope="pets:read pets:write"`},
+	}
+	for _, test := range tests {
+		_, err = o.Verify(test.header, test.scopes)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: error %v, want %v", test.header, errors.Unwrap(err), test.err)
+			continue
+		}
+		if err.Error() != test.challenge {
+			t.Errorf("%q: challenge %s, want %s", test.header, err, test.challenge)
+		}
+	}
+
+	o.Validator.(*Introspection).ClientSecret = "wrong"
+	if _, err = o.Verify("Bearer reader", nil); !errors.Is(err, ErrValidatorFailed) {
+		t.Errorf("introspection with wrong secret: %v", err)
+	}
+}
+
+func TestOAuth2_VerifyUnavailable(t *testing.T) {
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	for _, url := range []string{failing.URL, closed.URL} {
+		o := &OAuth2{Validator: &Introspection{URL: url}}
+		_, err := o.Verify("Bearer reader", nil)
+		if !errors.Is(err, ErrValidatorUnavailable) {
+			t.Errorf("%s: error %v, want %v", url, err, ErrValidatorUnavailable)
+		}
+		var challenge *BearerChallenge
+		if errors.As(err, &challenge) {
+			t.Errorf("%s: unexpected challenge %s", url, challenge.Challenge)
+		}
+	}
+
+	// Текст ошибки проверки не попадает в заголовок
+	o := &OAuth2{Validator: TokenValidatorFunc(func(token string) (*Token, error) {
+		return nil, errors.New("dial tcp 10.0.0.1:443: connection refused")
+	})}
+	_, err := o.Verify("Bearer reader", nil)
+	if want := `Bearer error="invalid_token", error_description="token is invalid"`; err == nil || err.Error() != want {
+		t.Errorf("challenge %v, want %s", err, want)
+	}
+}
@@ -1,11 +1,17 @@
 package security
 
+import (
+	"errors"
+	"strings"
+)
+
 const (
 	NoAuth     = ""
 	BasicAuth  = "Basic"
 	DigestAuth = "Digest"
 	ApiKeyAuth = "ApiKey"
 	OAuth2Auth = "OAuth2"
+	JWTAuth    = "JWT"
 )
 
 const (
@@ -14,13 +20,19 @@ const (
 	TypeOAuth2 = "oauth2"
 )
 
+// SchemeDigest схема HTTP авторизации Digest. В swagger 2.0 такого типа нет,
+// поэтому Digest описывается типом basic с расширением x-scheme
+const SchemeDigest = "digest"
+
 type Authorization struct {
 	AllRoutes    string
 	Unauthorized UnauthorizedHandler
+	Identify     IdentifyHandler
 	Basic        *Basic
 	Digest       *Digest
 	ApiKey       *ApiKey
 	OAuth2       *OAuth2
+	JWT          *JWT
 }
 
 type Definition struct {
@@ -32,25 +44,73 @@ type Definition struct {
 	AuthorizationURL string            `json:"authorizationUrl,omitempty"` // oauth2
 	TokenURL         string            `json:"tokenUrl,omitempty"`         // oauth2
 	Scopes           map[string]string `json:"scopes,omitempty"`           // oauth2
+	// BearerFormat формат токена Bearer, для OpenAPI 3 схема описывается как http bearer
+	BearerFormat string `json:"-"`
+	// Scheme схема HTTP авторизации вместо basic, для OpenAPI 3 схема описывается как http
+	Scheme string `json:"x-scheme,omitempty"`
 }
 
 type UnauthorizedHandler func(err error) bool
 
+// Errors ошибки всех не пройденных методов авторизации маршрута
+type Errors []error
+
+func (e Errors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Is совпадение с ошибкой любого из методов
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Init проверка настроек методов авторизации
+func (a Authorization) Init() error {
+	if a.Digest != nil {
+		if err := a.Digest.Init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type IAuthorization interface {
 	Do() (*Identity, error)
 	Definition() Definition
 }
 
+// Get вернуть схему авторизации по имени, nil если схема не настроена
 func (a Authorization) Get(auth string) IAuthorization {
 	switch auth {
 	case BasicAuth:
-		return a.Basic
+		if a.Basic != nil {
+			return a.Basic
+		}
 	case ApiKeyAuth:
-		return a.ApiKey
+		if a.ApiKey != nil {
+			return a.ApiKey
+		}
 	case DigestAuth:
-		return a.Digest
+		if a.Digest != nil {
+			return a.Digest
+		}
 	case OAuth2Auth:
-		return a.OAuth2
+		if a.OAuth2 != nil {
+			return a.OAuth2
+		}
+	case JWTAuth:
+		if a.JWT != nil {
+			return a.JWT
+		}
 	}
 	return nil
 }
@@ -1,14 +1,21 @@
 package egowebapi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/egovorukhin/egowebapi/consts"
 	"github.com/egovorukhin/egowebapi/security"
 	"github.com/mustan989/jsonschema"
+	"os"
+	"os/signal"
 	p "path"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 const (
@@ -22,12 +29,28 @@ type Server struct {
 	WebServer   IServer
 	Controllers []*Controller
 	Swagger     *Swagger
+	isBuilt     bool
+	routes      map[string]bool
+	pending     []pendingRoute
+	onStart     []Hook
+	onStop      []Hook
+	middleware  []Middleware
+	// Количество обрабатываемых запросов и флаг остановки
+	active   int64
+	draining int32
+}
+
+// pendingRoute маршрут, который будет добавлен в веб сервер после успешного Build
+type pendingRoute struct {
+	method  string
+	path    string
+	handler interface{}
 }
 
 type IServer interface {
 	Start(addr string) error
 	StartTLS(addr, cert, key string) error
-	Stop() error
+	Stop(ctx context.Context) error
 	Static(prefix, root string)
 	Any(path string, handler interface{})
 	Use(params ...interface{})
@@ -66,8 +89,8 @@ func New(server IServer, config Config) *Server {
 		Config:    config,
 		WebServer: server,
 		Swagger: &Swagger{
-			Swagger:             "2.0",
-			Host:                fmt.Sprintf("localhost:%d", config.Port),
+			Swagger:             OpenAPI20,
+			Host:                "localhost",
 			BasePath:            "/",
 			SecurityDefinitions: SecurityDefinitions{},
 			Paths:               Paths{},
@@ -87,15 +110,53 @@ func (s *Server) GetWebServer() interface{} {
 	return s.WebServer.GetApp()
 }
 
-// Start запуск сервера
-func (s *Server) Start() (err error) {
+// Build формирование маршрутов веб сервера и документации swagger без запуска слушателя.
+// Маршруты добавляются в веб сервер только после всех проверок, при ошибке документ
+// swagger и контроллеры возвращаются в исходное состояние, поэтому Build можно повторить.
+// Повторный вызов после успешного ничего не делает
+func (s *Server) Build() (err error) {
+
+	if s.isBuilt {
+		return nil
+	}
 
 	if s.Config.ContextHandler == nil {
 		return errors.New("Specify the handler - ContextHandler")
 	}
 
+	if err = s.Config.Authorization.Init(); err != nil {
+		return err
+	}
+
+	swagger := s.Swagger.snapshot()
+	controllers := make([]Controller, len(s.Controllers))
+	for i, c := range s.Controllers {
+		if c != nil {
+			controllers[i] = *c
+		}
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		*s.Swagger = swagger
+		for i, c := range s.Controllers {
+			if c != nil {
+				*c = controllers[i]
+			}
+		}
+		s.pending = nil
+	}()
+
+	s.routes = map[string]bool{}
+	s.pending = nil
+
 	for _, c := range s.Controllers {
 
+		if c.Interface == nil {
+			return errors.New("Controller is nil")
+		}
+
 		c.initialize(s.Swagger.BasePath)
 
 		// Добавляем тэги контроллера
@@ -103,88 +164,239 @@ func (s *Server) Start() (err error) {
 			s.Swagger.Tags = append(s.Swagger.Tags, c.Tag)
 		}
 
+		isMethod := false
 		// Проверка интерфейса на соответствие
 		if i, ok := c.Interface.(IGet); ok {
+			isMethod = true
 			err = s.get(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IPost); ok {
+			isMethod = true
 			err = s.post(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IPut); ok {
+			isMethod = true
 			err = s.put(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IDelete); ok {
+			isMethod = true
 			err = s.delete(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IOptions); ok {
+			isMethod = true
 			err = s.options(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IPatch); ok {
+			isMethod = true
 			err = s.patch(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IHead); ok {
+			isMethod = true
 			err = s.head(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(IConnect); ok {
+			isMethod = true
 			err = s.connect(i, c)
 			if err != nil {
 				return
 			}
 		}
 		if i, ok := c.Interface.(ITrace); ok {
+			isMethod = true
 			err = s.trace(i, c)
 			if err != nil {
 				return
 			}
 		}
+
+		if !isMethod {
+			return fmt.Errorf("Controller %s does not implement any HTTP method", c.Name)
+		}
+	}
+
+	// Маршруты документации
+	if s.Config.Docs != nil {
+		if err = s.addDocs(); err != nil {
+			return
+		}
+	}
+
+	// Установка порта в swagger
+	s.Swagger.setPort(s.addr())
+	// Добавляем схему в Swagger
+	if s.Config.Secure != nil {
+		s.Swagger.SetSchemes("https")
+	} else {
+		s.Swagger.SetSchemes("http")
+	}
+
+	// В строгом режиме замечания к документу swagger прерывают запуск
+	if s.Config.StrictSwagger {
+		if issues := s.Swagger.Lint(); len(issues) > 0 {
+			return issues
+		}
+	}
+
+	// Все проверки пройдены, добавляем маршруты в веб сервер
+	for _, route := range s.pending {
+		s.WebServer.Add(route.method, route.path, route.handler)
+	}
+	s.pending = nil
+
+	// Страница для ненайденных маршрутов
+	if s.Config.NotFoundPage != "" {
+		s.WebServer.NotFoundPage(s.Swagger.BasePath, s.Config.NotFoundPage)
+	}
+
+	s.isBuilt = true
+
+	return nil
+}
+
+// Listen запуск слушателя веб сервера, если маршруты еще не сформированы, то вызывается Build
+func (s *Server) Listen() (err error) {
+
+	err = s.Build()
+	if err != nil {
+		return
+	}
+
+	// Обработчики запуска
+	for _, hook := range s.onStart {
+		if err = hook(s); err != nil {
+			return
+		}
 	}
 
 	//Флаг старта
 	s.IsStarted = true
-	// Получение адреса
-	addr := fmt.Sprintf(":%d", s.Config.Port)
-	// Установка порта в swagger
-	s.Swagger.setPort(addr)
+	atomic.StoreInt32(&s.draining, 0)
 	// Если флаг для безопасности true, то запускаем механизм с TLS
 	if s.Config.Secure != nil {
-		// Добавляем схему в Swagger
-		s.Swagger.SetSchemes("https")
 		// Возвращаем данные по сертификату
 		cert, key := s.Config.Secure.Get()
 		// Запускаем слушатель с TLS настройкой
-		return s.WebServer.StartTLS(addr, cert, key)
+		return s.WebServer.StartTLS(s.addr(), cert, key)
 	}
-	// Добавляем схему в Swagger
-	s.Swagger.SetSchemes("http")
 	// Запуск слушателя веб сервера
-	return s.WebServer.Start(addr)
+	return s.WebServer.Start(s.addr())
 }
 
-// Stop Остановка сервера
-func (s *Server) Stop() error {
+// Start запуск сервера: формирование маршрутов и запуск слушателя
+func (s *Server) Start() error {
+	return s.Listen()
+}
+
+// addr адрес слушателя
+func (s *Server) addr() string {
+	return fmt.Sprintf(":%d", s.Config.Port)
+}
+
+// Run запуск сервера с ожиданием сигнала SIGINT/SIGTERM и плавной остановкой.
+// На остановку отводится Config.ShutdownTimeout
+func (s *Server) Run() error {
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.Listen()
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-sig:
+	}
+
+	timeout := s.Config.ShutdownTimeout
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return s.Stop(ctx)
+}
+
+// Stop Плавная остановка сервера. Новые запросы отклоняются с кодом 503,
+// обрабатываемые запросы завершаются до истечения срока контекста
+func (s *Server) Stop(ctx context.Context) (err error) {
+
+	atomic.StoreInt32(&s.draining, 1)
+
+	// Ожидаем завершения обрабатываемых запросов
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&s.active) > 0 && err == nil {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	if e := s.WebServer.Stop(ctx); e != nil && err == nil {
+		err = e
+	}
 	s.IsStarted = false
-	return s.WebServer.Stop()
+
+	// Обработчики остановки
+	for _, hook := range s.onStop {
+		if e := hook(s); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return
+}
+
+// OnStart Добавить обработчики, которые вызываются перед запуском слушателя
+func (s *Server) OnStart(hooks ...Hook) *Server {
+	s.onStart = append(s.onStart, hooks...)
+	return s
+}
+
+// OnStop Добавить обработчики, которые вызываются после остановки сервера
+func (s *Server) OnStop(hooks ...Hook) *Server {
+	s.onStop = append(s.onStop, hooks...)
+	return s
+}
+
+// drain учет обрабатываемых запросов, во время остановки сервера запросы отклоняются
+func (s *Server) drain(handler Handler) Handler {
+	return func(c *Context) error {
+		atomic.AddInt64(&s.active, 1)
+		defer atomic.AddInt64(&s.active, -1)
+		if atomic.LoadInt32(&s.draining) == 1 {
+			c.Set(consts.HeaderConnection, "close")
+			return c.SendStatus(consts.StatusServiceUnavailable)
+		}
+		return handler(c)
+	}
 }
 
 // Устанавливаем глобальные настройки для маршрутов
@@ -209,6 +421,7 @@ func (s *Server) newRoute() *Route {
 	}
 	if s.Config.Authorization.AllRoutes != security.NoAuth {
 		route.SetSecurity(s.Config.Authorization.AllRoutes)
+		route.defaultSecurity = s.Config.Authorization.AllRoutes
 	}
 
 	return route
@@ -280,6 +493,11 @@ func (s *Server) trace(i ITrace, c *Controller) error {
 // Добавить маршрут в веб сервер
 func (s *Server) add(method string, c *Controller, route *Route) error {
 
+	// Ошибка описания маршрута
+	if route.err != nil {
+		return fmt.Errorf("Controller %s: %s %s", c.Name, method, route.err)
+	}
+
 	// Если нет ни одного handler, то выходим
 	if route.Handler == nil {
 		return nil
@@ -294,7 +512,11 @@ func (s *Server) add(method string, c *Controller, route *Route) error {
 	// Авторизация в swagger
 	for _, sec := range route.Security {
 		for key := range sec {
-			s.Swagger.setSecurityDefinition(key, s.Config.Authorization.Get(key).Definition())
+			auth := s.Config.Authorization.Get(key)
+			if auth == nil {
+				return fmt.Errorf("Controller %s: authorization %s is not configured", c.Name, key)
+			}
+			s.Swagger.setSecurityDefinition(key, auth.Definition())
 		}
 	}
 
@@ -309,8 +531,27 @@ func (s *Server) add(method string, c *Controller, route *Route) error {
 	// Добавляем ссылку на тэг в контроллере
 	route.Operation.addTag(c.Tag.Name)
 
-	// Получаем handler маршрута
-	h := s.Config.ContextHandler(route.getHandler(s.Config, s.Swagger))
+	// Требования контроллера к ролям и областям доступа
+	// Роли контроллера и маршрута проверяются отдельно: нужна роль из каждого списка
+	route.ControllerRoles = appendUnique(nil, c.roles...)
+	route.RequiredScopes = appendUnique(appendUnique(nil, c.scopes...), route.RequiredScopes...)
+	if len(route.ControllerRoles) > 0 || len(route.RequiredRoles) > 0 || len(route.RequiredScopes) > 0 {
+		if _, ok := route.Responses[strconv.Itoa(consts.StatusForbidden)]; !ok {
+			route.setResponse(consts.StatusForbidden, "", false, nil, "Forbidden")
+		}
+	}
+
+	// Ответ при ошибке проверки параметров
+	if route.isValidation || s.Config.Validation {
+		if _, ok := route.Responses[strconv.Itoa(consts.StatusBadRequest)]; !ok {
+			route.setResponse(consts.StatusBadRequest, "", false, nil, "Invalid request parameters")
+		}
+		// Схема тела запроса до того, как swagger заменит имена моделей ссылками
+		route.body = newBodySchema(s.Swagger, route.Parameters)
+		if _, ok := route.Responses[strconv.Itoa(consts.StatusUnprocessableEntity)]; !ok && route.body != nil {
+			route.setResponse(consts.StatusUnprocessableEntity, "", false, nil, "Invalid request body")
+		}
+	}
 
 	// Перебираем параметры адресной строки
 	for _, param := range params {
@@ -318,6 +559,13 @@ func (s *Server) add(method string, c *Controller, route *Route) error {
 		// Объединяем путь и параметры
 		fullPath := p.Join(c.Path, param)
 
+		// Получаем handler маршрута с шаблоном пути и оборачиваем его промежуточными обработчиками
+		handler := route.getHandler(s.Config, s.Swagger, method, fullPath)
+		handler = wrap(handler, route.middleware)
+		handler = wrap(handler, c.middleware)
+		handler = wrap(handler, s.middleware)
+		h := s.Config.ContextHandler(s.drain(s.recover(handler)))
+
 		// Проверка на соответствие базового пути
 		ok, l := s.Swagger.compareBasePath(c.Path)
 		if ok && c.IsShow {
@@ -348,13 +596,34 @@ func (s *Server) add(method string, c *Controller, route *Route) error {
 		// Корректировка параметров пути
 		fullPath = s.convertParams(fullPath)
 
-		// Добавляем метод, путь и обработчик
-		s.WebServer.Add(method, fullPath, h)
+		// Проверка на дублирование маршрута
+		key := method + " " + fullPath
+		if s.routes[key] {
+			return fmt.Errorf("Controller %s: route %s is already registered", c.Name, key)
+		}
+		s.routes[key] = true
+
+		// Метод, путь и обработчик добавляются в веб сервер в конце Build
+		s.pending = append(s.pending, pendingRoute{method: method, path: fullPath, handler: h})
 	}
 
 	return nil
 }
 
+// Use Добавить промежуточные обработчики для всех маршрутов сервера
+func (s *Server) Use(middleware ...Middleware) *Server {
+	s.middleware = append(s.middleware, middleware...)
+	return s
+}
+
+// wrap оборачиваем обработчик промежуточными обработчиками, первый в списке выполняется первым
+func wrap(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
 // Register Регистрация контроллера
 func (s *Server) Register(i interface{}) *Controller {
 	controller := &Controller{
@@ -0,0 +1,254 @@
+package egowebapi
+
+import (
+	"context"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"github.com/egovorukhin/egowebapi/security"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServer веб сервер, который только запоминает маршруты
+type testServer struct {
+	routes []string
+}
+
+func (s *testServer) Start(addr string) error               { return nil }
+func (s *testServer) StartTLS(addr, cert, key string) error { return nil }
+func (s *testServer) Stop(ctx context.Context) error        { return nil }
+func (s *testServer) Static(prefix, root string)            {}
+func (s *testServer) Any(path string, handler interface{})  {}
+func (s *testServer) Use(params ...interface{})             {}
+func (s *testServer) GetApp() interface{}                   { return nil }
+func (s *testServer) NotFoundPage(path, page string)        {}
+func (s *testServer) ConvertParam(param string) string      { return ":" + param }
+func (s *testServer) Add(method, path string, handler interface{}) {
+	s.routes = append(s.routes, method+" "+path)
+}
+
+func newTestServer(config Config) (*Server, *testServer) {
+	ts := &testServer{}
+	config.ContextHandler = func(handler Handler) interface{} {
+		return handler
+	}
+	return New(ts, config), ts
+}
+
+type Empty struct{}
+
+type Basic struct{}
+
+func (Basic) Get(route *Route) {
+	route.SetSecurity(security.BasicAuth)
+	route.Handler = func(c *Context) error {
+		return nil
+	}
+}
+
+func TestServer_Build(t *testing.T) {
+
+	s, ts := newTestServer(Config{Port: 8080})
+	s.Register(new(User)).SetPath("/api/user")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.routes) != 0 {
+		t.Errorf("routes %v, want none: handler is empty", ts.routes)
+	}
+	if s.Swagger.Host != "localhost:8080" {
+		t.Errorf("host %s, want localhost:8080", s.Swagger.Host)
+	}
+
+	tests := []struct {
+		name     string
+		register func(s *Server)
+		err      string
+	}{
+		{"nil", func(s *Server) { s.Register(nil) }, "nil"},
+		{"no methods", func(s *Server) { s.Register(new(Empty)).SetPath("/api/empty") }, "does not implement"},
+		{"auth", func(s *Server) { s.Register(new(Basic)).SetPath("/api/basic") }, "not configured"},
+		{"duplicate", func(s *Server) {
+			s.Config.Authorization.Basic = &security.Basic{}
+			s.Register(new(Basic)).SetPath("/api/basic")
+			s.Register(new(Basic)).SetPath("/api/basic")
+		}, "already registered"},
+	}
+
+	for _, test := range tests {
+		s, _ := newTestServer(Config{})
+		test.register(s)
+		err := s.Build()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+
+	// Неудачный Build не добавляет маршруты, повторный вызов регистрирует их один раз
+	s, ts = newTestServer(Config{Authorization: security.Authorization{Basic: &security.Basic{}}})
+	s.Register(new(Basic)).SetPath("/api/basic")
+	s.Register(new(Empty)).SetPath("/api/empty")
+	if err := s.Build(); err == nil {
+		t.Fatal("want error: controller without methods")
+	}
+	if len(ts.routes) != 0 || len(s.Swagger.Tags) != 0 || len(s.Swagger.Paths) != 0 {
+		t.Errorf("failed build: routes %v, tags %v, paths %v", ts.routes, s.Swagger.Tags, s.Swagger.Paths)
+	}
+	s.Controllers = s.Controllers[:1]
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ts.routes, ",") != "GET /api/basic" || len(s.Swagger.Tags) != 1 || len(s.Swagger.Schemes) != 1 {
+		t.Errorf("retry: routes %v, tags %v, schemes %v", ts.routes, s.Swagger.Tags, s.Swagger.Schemes)
+	}
+}
+
+type Slow struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *Slow) Get(route *Route) {
+	route.Handler = func(c *Context) error {
+		close(s.started)
+		<-s.release
+		return c.SendString(200, "done")
+	}
+}
+
+func TestServer_Stop(t *testing.T) {
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(NewContext(c))
+			})
+		},
+	})
+	slow := &Slow{started: make(chan struct{}), release: make(chan struct{})}
+	s.Register(slow).SetPath("/slow")
+
+	stopped := false
+	s.OnStop(func(s *Server) error {
+		stopped = true
+		return nil
+	})
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Запрос в обработке
+	inFlight := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		web.ServeHTTP(inFlight, httptest.NewRequest("GET", "/slow", nil))
+		close(done)
+	}()
+	<-slow.started
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.Stop(context.Background())
+	}()
+
+	// Новый запрос во время остановки отклоняется
+	for atomic.LoadInt32(&s.draining) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	w := httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if w.Code != 503 {
+		t.Errorf("status %d, want 503", w.Code)
+	}
+
+	close(slow.release)
+	<-done
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	if inFlight.Code != 200 || inFlight.Body.String() != "done" {
+		t.Errorf("in-flight request: %d %s", inFlight.Code, inFlight.Body.String())
+	}
+	if !stopped {
+		t.Error("OnStop hook was not called")
+	}
+
+	// Истечение срока контекста при незавершенных запросах
+	atomic.AddInt64(&s.active, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+type Trace struct {
+	trace *[]string
+}
+
+func (t Trace) Get(route *Route) {
+	route.SetSecurity(security.BasicAuth).Use(t.middleware("route"))
+	route.Handler = func(c *Context) error {
+		*t.trace = append(*t.trace, "handler")
+		return c.SendStatus(200)
+	}
+}
+
+func (t Trace) middleware(name string) Middleware {
+	return func(next Handler) Handler {
+		return func(c *Context) error {
+			*t.trace = append(*t.trace, name)
+			return next(c)
+		}
+	}
+}
+
+func TestServer_Use(t *testing.T) {
+
+	var trace []string
+	tr := Trace{trace: &trace}
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		Authorization: security.Authorization{
+			Basic: &security.Basic{
+				Handler: func(user string, pass string) bool {
+					return true
+				},
+			},
+		},
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(NewContext(c))
+			})
+		},
+	})
+	s.Use(tr.middleware("server1"), tr.middleware("server2"))
+	s.Register(tr).SetPath("/trace").Use(tr.middleware("controller"))
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/trace", nil)
+	r.SetBasicAuth("user", "pass")
+	web.ServeHTTP(httptest.NewRecorder(), r)
+	want := "server1,server2,controller,route,handler"
+	if got := strings.Join(trace, ","); got != want {
+		t.Errorf("trace %s, want %s", got, want)
+	}
+
+	// Промежуточные обработчики выполняются и при ошибке авторизации
+	trace = nil
+	w := httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("GET", "/trace", nil))
+	want = "server1,server2,controller,route"
+	if got := strings.Join(trace, ","); got != want || w.Code != 401 {
+		t.Errorf("trace %s, status %d, want %s, 401", got, w.Code, want)
+	}
+}
@@ -0,0 +1,295 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/egovorukhin/egowebapi/session"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FlashesKey ключ, под которым Render передает сообщения в шаблон
+const FlashesKey = "Flashes"
+
+var ErrSessionKeyNotFound = errors.New("session key not found")
+
+// Session сессия пользователя. Значения и сообщения сохраняются в хранилище сессий
+// после выполнения обработчика маршрута, а при хранении в cookie - перед отправкой ответа
+type Session struct {
+	Key      string
+	Value    string
+	User     string
+	Created  time.Time
+	LastTime time.Time
+	values   map[string]json.RawMessage
+	flashes  []session.Flash
+	changed  bool
+	expires  time.Time
+}
+
+// newSession сессия из данных хранилища
+func newSession(key, value string, data *session.Data) *Session {
+	if data.ID != "" {
+		value = data.ID
+	}
+	return &Session{
+		Key:      key,
+		Value:    value,
+		User:     data.User,
+		Created:  data.Created,
+		LastTime: time.Now(),
+		values:   data.Values,
+		flashes:  data.Flashes,
+		expires:  data.Expires,
+	}
+}
+
+// data данные для сохранения в хранилище
+func (s *Session) data() *session.Data {
+	return &session.Data{
+		ID:       s.Value,
+		User:     s.User,
+		Created:  s.Created,
+		LastTime: s.LastTime,
+		Values:   s.values,
+		Flashes:  s.flashes,
+	}
+}
+
+// Set Сохранить значение в сессии, значение сериализуется в json
+func (s *Session) Set(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if s.values == nil {
+		s.values = map[string]json.RawMessage{}
+	}
+	s.values[key] = b
+	s.changed = true
+	return nil
+}
+
+// Get Получить значение сессии в v, ErrSessionKeyNotFound если значения нет
+func (s *Session) Get(key string, v interface{}) error {
+	b, ok := s.values[key]
+	if !ok {
+		return ErrSessionKeyNotFound
+	}
+	return json.Unmarshal(b, v)
+}
+
+// GetString Получить строковое значение сессии, пустая строка если значения нет
+func (s *Session) GetString(key string) string {
+	var value string
+	_ = s.Get(key, &value)
+	return value
+}
+
+// Has Наличие значения в сессии
+func (s *Session) Has(key string) bool {
+	_, ok := s.values[key]
+	return ok
+}
+
+// Delete Удалить значение из сессии
+func (s *Session) Delete(key string) {
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.changed = true
+	}
+}
+
+// AddFlash Добавить одноразовое сообщение, например для перехода после POST запроса.
+// Сессия должна существовать, см. Context.LoadSession
+func (s *Session) AddFlash(flashType, message string) {
+	s.flashes = append(s.flashes, session.Flash{Type: flashType, Message: message})
+	s.changed = true
+}
+
+// Flashes Получить сообщения, после чтения они удаляются из сессии
+func (s *Session) Flashes() []session.Flash {
+	flashes := s.flashes
+	if len(flashes) > 0 {
+		s.flashes = nil
+		s.changed = true
+	}
+	return flashes
+}
+
+// LoadSession Сессия запроса. На маршрутах без проверки сессии Context.Session
+// не заполняется, данные загружаются из хранилища при первом вызове.
+// Если у клиента нет действующей сессии, то возвращается nil и новая сессия не создается,
+// поэтому добавить сообщение AddFlash на таком маршруте нельзя - для этого маршрут
+// должен создавать сессию через Route.Session(On)
+func (c *Context) LoadSession() *Session {
+	if c.Session == nil && c.loadSession != nil {
+		load := c.loadSession
+		c.loadSession = nil
+		c.Session = load()
+	}
+	return c.Session
+}
+
+// Render Формирование шаблона. Если в сессии есть сообщения, то они передаются
+// в шаблон под ключом FlashesKey, когда данные не указаны или являются Map
+func (c *Context) Render(name string, data interface{}, layouts ...string) error {
+	if c.LoadSession() != nil && len(c.Session.flashes) > 0 {
+		switch m := data.(type) {
+		case nil:
+			data = Map{FlashesKey: c.Session.Flashes()}
+		case Map:
+			data = withFlashes(m, c.Session)
+		case map[string]interface{}:
+			data = withFlashes(m, c.Session)
+		}
+	}
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.Render(name, data, layouts...)
+}
+
+// withFlashes копия данных шаблона с сообщениями сессии
+func withFlashes(m map[string]interface{}, s *Session) Map {
+	data := Map{}
+	for key, value := range m {
+		data[key] = value
+	}
+	if _, ok := data[FlashesKey]; !ok {
+		data[FlashesKey] = s.Flashes()
+	}
+	return data
+}
+
+// sessionCookie значение cookie сессии, при хранении в cookie собирается из всех частей
+func sessionCookie(c *Context, config *session.Config) string {
+	if config.Cookie == nil {
+		return c.Cookies(config.KeyName)
+	}
+	var value strings.Builder
+	for i := 0; i < config.Cookie.Chunks(); i++ {
+		chunk := c.Cookies(session.ChunkName(config.KeyName, i))
+		if chunk == "" {
+			break
+		}
+		value.WriteString(chunk)
+	}
+	return value.String()
+}
+
+// clearSessionCookie удаление cookie сессии со всеми частями, начиная с from
+func clearSessionCookie(c *Context, config *session.Config, from int) {
+	if config.Cookie == nil {
+		c.ClearCookie(config.KeyName)
+		return
+	}
+	for i := from; i < config.Cookie.Chunks(); i++ {
+		if name := session.ChunkName(config.KeyName, i); i == 0 || c.Cookies(name) != "" {
+			c.SetCookie(config.Cookie.HTTPCookie(name, "", time.Time{}))
+		}
+	}
+}
+
+// saveSessionCookie запись данных сессии в cookie. Новая сессия записывается,
+// только если указан пользователь, без изменений cookie перезаписываются,
+// только когда прошла половина срока жизни
+func (c *Context) saveSessionCookie(config *session.Config, force bool) error {
+	s := c.Session
+	if s == nil || !((force && s.User != "") || s.changed || (!s.expires.IsZero() && time.Until(s.expires) < config.Expires/2)) {
+		return nil
+	}
+	data := s.data()
+	chunks, err := config.Encode(data)
+	if err != nil {
+		return err
+	}
+	for i, chunk := range chunks {
+		c.SetCookie(config.Cookie.HTTPCookie(session.ChunkName(s.Key, i), chunk, data.Expires))
+	}
+	// Удаляем лишние части предыдущего значения
+	clearSessionCookie(c, config, len(chunks))
+	s.expires = data.Expires
+	s.changed = false
+	return nil
+}
+
+// saveSessionStore сохранение новой сессии в хранилище и отправка cookie с ее ключом.
+// Пустая сессия без пользователя не сохраняется, и cookie для нее не отправляется
+func (c *Context) saveSessionStore(config *session.Config) error {
+	s := c.Session
+	if s == nil || (s.User == "" && !s.changed) {
+		return nil
+	}
+	if err := config.Save(s.Value, s.data()); err != nil {
+		return err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:    s.Key,
+		Value:   s.Value,
+		Expires: time.Now().Add(config.Expires),
+	})
+	s.changed = false
+	return nil
+}
+
+// commitSession запись отложенной сессии до отправки ответа, выполняется один раз
+func (c *Context) commitSession() error {
+	if c.commit == nil {
+		return nil
+	}
+	commit := c.commit
+	c.commit = nil
+	return commit()
+}
+
+func (c *Context) SendStatus(code int) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.SendStatus(code)
+}
+
+func (c *Context) Send(code int, contentType string, b []byte) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.Send(code, contentType, b)
+}
+
+func (c *Context) SendString(code int, s string) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.SendString(code, s)
+}
+
+func (c *Context) SendFile(file string) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.SendFile(file)
+}
+
+func (c *Context) SendStream(code int, contentType string, stream io.Reader) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.SendStream(code, contentType, stream)
+}
+
+func (c *Context) Redirect(location string, status int) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.Redirect(location, status)
+}
+
+func (c *Context) JSON(code int, data interface{}) error {
+	if err := c.commitSession(); err != nil {
+		return err
+	}
+	return c.IContext.JSON(code, data)
+}
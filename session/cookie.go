@@ -0,0 +1,198 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCookieChunkSize = 3800
+	DefaultCookieMaxChunks = 4
+)
+
+var (
+	ErrCookieKeys     = errors.New("session cookie: keys are not set")
+	ErrCookieInvalid  = errors.New("session cookie: invalid value")
+	ErrCookieExpired  = errors.New("session cookie: expired")
+	ErrCookieTooLarge = errors.New("session cookie: value is too large")
+)
+
+// Cookie хранение сессии в cookie без состояния на сервере. Данные сессии
+// подписываются HMAC-SHA256 или шифруются AES-GCM (Encrypt), для чего ключ должен
+// иметь длину 16, 24 или 32 байта. Первый ключ используется для записи,
+// остальные только для проверки, что позволяет менять ключи. Значение больше
+// ChunkSize разбивается на несколько cookie: <KeyName>, <KeyName>_1, ...
+type Cookie struct {
+	Keys      [][]byte
+	Encrypt   bool
+	ChunkSize int
+	MaxChunks int
+	// Secure и SameSite применяются ко всем частям, в том числе при удалении
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// HTTPCookie cookie части сессии с атрибутами конфигурации. Нулевое
+// время окончания означает удаление cookie
+func (c *Cookie) HTTPCookie(name, value string, expires time.Time) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   c.Secure,
+		HttpOnly: true,
+		SameSite: c.SameSite,
+	}
+	if expires.IsZero() {
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(0, 0)
+	}
+	return cookie
+}
+
+func (c *Cookie) chunkSize() int {
+	if c.ChunkSize <= 0 {
+		return DefaultCookieChunkSize
+	}
+	return c.ChunkSize
+}
+
+// Chunks максимальное количество cookie одной сессии
+func (c *Cookie) Chunks() int {
+	if c.MaxChunks <= 0 {
+		return DefaultCookieMaxChunks
+	}
+	return c.MaxChunks
+}
+
+// ChunkName имя cookie с номером части
+func ChunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return name + "_" + strconv.Itoa(i)
+}
+
+// Encode сериализация данных сессии в значения cookie по частям. Имя cookie
+// участвует в подписи, поэтому значение нельзя перенести в другую cookie
+func (c *Cookie) Encode(name string, data *Data) ([]string, error) {
+
+	if len(c.Keys) == 0 {
+		return nil, ErrCookieKeys
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var value string
+	if c.Encrypt {
+		gcm, err := newGCM(c.Keys[0])
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		value = base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, payload, []byte(name)))
+	} else {
+		value = base64.RawURLEncoding.EncodeToString(payload)
+		value += "." + base64.RawURLEncoding.EncodeToString(sign(c.Keys[0], name, value))
+	}
+
+	size := c.chunkSize()
+	var chunks []string
+	for len(value) > size {
+		chunks = append(chunks, value[:size])
+		value = value[size:]
+	}
+	chunks = append(chunks, value)
+	if len(chunks) > c.Chunks() {
+		return nil, ErrCookieTooLarge
+	}
+	return chunks, nil
+}
+
+// Decode проверка и разбор значения cookie, собранного из всех частей
+func (c *Cookie) Decode(name, value string) (*Data, error) {
+
+	if len(c.Keys) == 0 {
+		return nil, ErrCookieKeys
+	}
+	if value == "" {
+		return nil, ErrNotFound
+	}
+
+	var payload []byte
+	if c.Encrypt {
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return nil, ErrCookieInvalid
+		}
+		for _, key := range c.Keys {
+			gcm, err := newGCM(key)
+			if err != nil {
+				return nil, err
+			}
+			if len(b) < gcm.NonceSize() {
+				return nil, ErrCookieInvalid
+			}
+			if payload, err = gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], []byte(name)); err == nil {
+				break
+			}
+		}
+	} else {
+		i := strings.LastIndexByte(value, '.')
+		if i < 0 {
+			return nil, ErrCookieInvalid
+		}
+		mac, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+		if err != nil {
+			return nil, ErrCookieInvalid
+		}
+		for _, key := range c.Keys {
+			if hmac.Equal(mac, sign(key, name, value[:i])) {
+				payload, _ = base64.RawURLEncoding.DecodeString(value[:i])
+				break
+			}
+		}
+	}
+	if payload == nil {
+		return nil, ErrCookieInvalid
+	}
+
+	data := &Data{}
+	if err := json.Unmarshal(payload, data); err != nil {
+		return nil, ErrCookieInvalid
+	}
+	if !data.Expires.IsZero() && time.Now().After(data.Expires) {
+		return nil, ErrCookieExpired
+	}
+	return data, nil
+}
+
+func sign(key []byte, name, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "|" + value))
+	return mac.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookie(t *testing.T) {
+
+	oldKey := []byte("0123456789abcdef")
+	newKey := []byte("fedcba9876543210")
+
+	for _, encrypt := range []bool{false, true} {
+		c := &Cookie{Keys: [][]byte{oldKey}, Encrypt: encrypt}
+		data := &Data{
+			ID:      "id",
+			User:    "alice",
+			Expires: time.Now().Add(time.Minute),
+		}
+		chunks, err := c.Encode("session_id", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value := strings.Join(chunks, "")
+		if encrypt && strings.Contains(value, "alice") {
+			t.Error("encrypted value contains user")
+		}
+		if d, err := c.Decode("session_id", value); err != nil || d.User != "alice" || d.ID != "id" {
+			t.Errorf("decode (encrypt %v): %v %v", encrypt, d, err)
+		}
+
+		// Значение привязано к имени cookie
+		if _, err = c.Decode("other", value); !errors.Is(err, ErrCookieInvalid) {
+			t.Errorf("other name (encrypt %v): %v", encrypt, err)
+		}
+		b := []byte(value)
+		b[len(b)/2] ^= 1
+		if _, err = c.Decode("session_id", string(b)); !errors.Is(err, ErrCookieInvalid) {
+			t.Errorf("tampered (encrypt %v): %v", encrypt, err)
+		}
+
+		// Смена ключей: старый ключ принимается для проверки
+		rotated := &Cookie{Keys: [][]byte{newKey, oldKey}, Encrypt: encrypt}
+		if _, err = rotated.Decode("session_id", value); err != nil {
+			t.Errorf("rotated (encrypt %v): %v", encrypt, err)
+		}
+		removed := &Cookie{Keys: [][]byte{newKey}, Encrypt: encrypt}
+		if _, err = removed.Decode("session_id", value); !errors.Is(err, ErrCookieInvalid) {
+			t.Errorf("removed key (encrypt %v): %v", encrypt, err)
+		}
+
+		data.Expires = time.Now().Add(-time.Second)
+		chunks, _ = c.Encode("session_id", data)
+		if _, err = c.Decode("session_id", strings.Join(chunks, "")); !errors.Is(err, ErrCookieExpired) {
+			t.Errorf("expired (encrypt %v): %v", encrypt, err)
+		}
+	}
+}
+
+func TestCookie_Chunks(t *testing.T) {
+
+	c := &Cookie{Keys: [][]byte{[]byte("secret")}, ChunkSize: 200, MaxChunks: 3}
+	data := &Data{User: strings.Repeat("a", 300)}
+	chunks, err := c.Encode("session_id", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("chunks %d, want 3", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 200 {
+			t.Errorf("chunk length %d", len(chunk))
+		}
+	}
+	if d, err := c.Decode("session_id", strings.Join(chunks, "")); err != nil || d.User != data.User {
+		t.Errorf("decode: %v", err)
+	}
+
+	data.User = strings.Repeat("a", 1000)
+	if _, err = c.Encode("session_id", data); !errors.Is(err, ErrCookieTooLarge) {
+		t.Errorf("too large: %v", err)
+	}
+	if name := ChunkName("session_id", 2); name != "session_id_2" {
+		t.Errorf("chunk name %s", name)
+	}
+}
@@ -0,0 +1,127 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileExt расширение файлов сессий
+const fileExt = ".session"
+
+// FileStore хранилище сессий в файлах каталога. Имя файла - хэш идентификатора сессии,
+// время изменения файла - время окончания срока жизни сессии
+type FileStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileStore Инициализация файлового хранилища, каталог создается при необходимости
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// filename путь к файлу сессии. Идентификатор приходит из cookie,
+// поэтому в имени файла используется только его хэш
+func (s *FileStore) filename(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+fileExt)
+}
+
+func (s *FileStore) Get(id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	name := s.filename(id)
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(info.ModTime()) {
+		return nil, ErrNotFound
+	}
+	return ioutil.ReadFile(name)
+}
+
+func (s *FileStore) Set(id string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Запись через временный файл, чтобы читатели не увидели неполное значение
+	f, err := ioutil.TempFile(s.dir, "tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(value)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		expires := time.Now().Add(ttl)
+		err = os.Chtimes(tmp, expires, expires)
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.filename(id))
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+func (s *FileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.filename(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Touch(id string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := s.filename(id)
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if time.Now().After(info.ModTime()) {
+		return ErrNotFound
+	}
+	expires := time.Now().Add(ttl)
+	return os.Chtimes(name, expires, expires)
+}
+
+func (s *FileStore) GC() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, info := range files {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), fileExt) || !now.After(info.ModTime()) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(s.dir, info.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
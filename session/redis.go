@@ -0,0 +1,246 @@
+package session
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisStore хранилище сессий в Redis (или совместимом сервере) по протоколу RESP.
+// Срок жизни сессий отслеживает сам сервер, поэтому GC ничего не делает
+type RedisStore struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix префикс ключей, по умолчанию "session:"
+	Prefix string
+	// Timeout тайм-аут подключения и выполнения команды, по умолчанию 5 секунд
+	Timeout time.Duration
+	// MaxIdle количество сохраняемых соединений, по умолчанию 4
+	MaxIdle int
+
+	pool chan *redisConn
+	once sync.Once
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// redisError ошибка, которую вернул сервер
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// NewRedisStore Инициализация хранилища Redis
+func NewRedisStore(addr string) *RedisStore {
+	return &RedisStore{Addr: addr}
+}
+
+func (s *RedisStore) key(id string) string {
+	if s.Prefix == "" {
+		return "session:" + id
+	}
+	return s.Prefix + id
+}
+
+func (s *RedisStore) Get(id string) ([]byte, error) {
+	reply, err := s.do("GET", s.key(id))
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNotFound
+	}
+	b, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected reply %v", reply)
+	}
+	return b, nil
+}
+
+func (s *RedisStore) Set(id string, value []byte, ttl time.Duration) error {
+	_, err := s.do("SET", s.key(id), string(value), "PX", milliseconds(ttl))
+	return err
+}
+
+func (s *RedisStore) Delete(id string) error {
+	_, err := s.do("DEL", s.key(id))
+	return err
+}
+
+func (s *RedisStore) Touch(id string, ttl time.Duration) error {
+	reply, err := s.do("PEXPIRE", s.key(id), milliseconds(ttl))
+	if err != nil {
+		return err
+	}
+	if n, _ := reply.(int64); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// milliseconds срок жизни в миллисекундах для PX и PEXPIRE. Redis не принимает 0,
+// поэтому срок меньше миллисекунды округляется до 1, и сессия истекает почти сразу, как в других хранилищах
+func milliseconds(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+func (s *RedisStore) GC() error {
+	return nil
+}
+
+// Close закрыть сохраненные соединения
+func (s *RedisStore) Close() error {
+	for {
+		select {
+		case conn := <-s.pool:
+			_ = conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do выполнить команду. Соединение возвращается в пул, если команда выполнена без сетевой ошибки
+func (s *RedisStore) do(args ...string) (interface{}, error) {
+
+	conn, err := s.conn()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.command(s.timeout(), args...)
+	var serverErr redisError
+	if err != nil && !errors.As(err, &serverErr) {
+		_ = conn.Close()
+		return nil, err
+	}
+	s.release(conn)
+	return reply, err
+}
+
+func (s *RedisStore) timeout() time.Duration {
+	if s.Timeout == 0 {
+		return 5 * time.Second
+	}
+	return s.Timeout
+}
+
+// conn соединение из пула или новое с авторизацией и выбором базы
+func (s *RedisStore) conn() (*redisConn, error) {
+
+	s.once.Do(func() {
+		size := s.MaxIdle
+		if size <= 0 {
+			size = 4
+		}
+		s.pool = make(chan *redisConn, size)
+	})
+	select {
+	case conn := <-s.pool:
+		return conn, nil
+	default:
+	}
+
+	c, err := net.DialTimeout("tcp", s.Addr, s.timeout())
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: c, r: bufio.NewReader(c)}
+	if s.Password != "" {
+		if _, err = conn.command(s.timeout(), "AUTH", s.Password); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if s.DB != 0 {
+		if _, err = conn.command(s.timeout(), "SELECT", strconv.Itoa(s.DB)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (s *RedisStore) release(conn *redisConn) {
+	select {
+	case s.pool <- conn:
+	default:
+		_ = conn.Close()
+	}
+}
+
+// command отправить команду и прочитать ответ
+func (c *redisConn) command(timeout time.Duration, args ...string) (interface{}, error) {
+
+	if err := c.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	b := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b = append(b, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		b = append(b, arg...)
+		b = append(b, "\r\n"...)
+	}
+	if _, err := c.Write(b); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// readReply чтение ответа RESP: строка, ошибка, число, строка с длиной или массив
+func readReply(r *bufio.Reader) (interface{}, error) {
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: invalid reply")
+	}
+	kind, line := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return nil, redisError(line)
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
@@ -1,12 +1,16 @@
 package session
 
 import (
+	"encoding/json"
 	"github.com/egovorukhin/egowebapi/consts"
 	"github.com/egovorukhin/egowebapi/security"
 	"github.com/gofiber/fiber/v2/utils"
 	"time"
 )
 
+// DefaultGCInterval интервал удаления просроченных сессий хранилища по умолчанию
+const DefaultGCInterval = time.Minute
+
 // Config структура, которая описывает сессию
 type Config struct {
 	RedirectPath        string
@@ -16,7 +20,28 @@ type Config struct {
 	SessionHandler      Handler
 	GenSessionIdHandler GenSessionIdHandler
 	KeyName             string
-	m                   map[string]string
+	// Store хранилище сессий, по умолчанию MemoryStore
+	Store Store
+	// Cookie хранение данных сессии в самих cookie, хранилище не используется
+	Cookie *Cookie
+}
+
+// Data данные сессии в хранилище
+type Data struct {
+	ID       string                     `json:"id,omitempty"`
+	User     string                     `json:"user"`
+	Created  time.Time                  `json:"created"`
+	LastTime time.Time                  `json:"last_time"`
+	Values   map[string]json.RawMessage `json:"values,omitempty"`
+	Flashes  []Flash                    `json:"flashes,omitempty"`
+	// Expires окончание срока жизни, используется при хранении в cookie
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Flash одноразовое сообщение, которое удаляется из сессии после чтения
+type Flash struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
 }
 
 type Handler func(value string) (user string, err error)
@@ -24,8 +49,10 @@ type GenSessionIdHandler func() string
 
 func (s *Config) Default() {
 
-	// Хэш с данными
-	s.m = map[string]string{}
+	// Хранилище сессий
+	if s.Store == nil && s.Cookie == nil {
+		s.Store = NewMemoryStore(DefaultGCInterval)
+	}
 	// Имя ключа сессии
 	if s.KeyName == "" {
 		s.KeyName = "session_id"
@@ -42,10 +69,23 @@ func (s *Config) Default() {
 	if s.RedirectStatus == 0 {
 		s.RedirectStatus = consts.StatusFound
 	}
-	// Обработчик сессии
+	// Обработчик сессии, по умолчанию пользователь из хранилища с продлением срока жизни
 	if s.SessionHandler == nil {
 		s.SessionHandler = func(value string) (user string, err error) {
-			return s.get(value), nil
+			data, err := s.Load(value)
+			if err != nil {
+				return "", err
+			}
+			if data.User == "" {
+				return "", ErrAnonymous
+			}
+			if s.Cookie != nil {
+				return data.User, nil
+			}
+			if err = s.Store.Touch(value, s.Expires); err != nil {
+				return "", err
+			}
+			return data.User, nil
 		}
 	}
 	// Обработчик генерации SessionId
@@ -56,22 +96,50 @@ func (s *Config) Default() {
 	}
 }
 
-// Добавить запись в хэш сессий
-func (s *Config) add(id string, user string) {
-	s.m[id] = user
+// Load Загрузить данные сессии из хранилища, при хранении в cookie id - значение cookie
+func (s *Config) Load(id string) (*Data, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+	if s.Cookie != nil {
+		return s.Cookie.Decode(s.KeyName, id)
+	}
+	b, err := s.Store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	data := &Data{}
+	if err = json.Unmarshal(b, data); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
-// Вернуть сессию из хэша
-func (s *Config) get(id string) string {
-	if value, ok := s.m[id]; ok {
-		return value
+// Save Сохранить данные сессии в хранилище на время Expires. При хранении
+// в cookie данные записываются в ответ через Encode
+func (s *Config) Save(id string, data *Data) error {
+	if s.Cookie != nil {
+		return nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
 	}
-	return ""
+	return s.Store.Set(id, b, s.Expires)
 }
 
-// Удалить из хэша сессию
-func (s *Config) delete(id string) {
-	delete(s.m, id)
+// Delete Удалить сессию из хранилища
+func (s *Config) Delete(id string) error {
+	if id == "" || s.Cookie != nil {
+		return nil
+	}
+	return s.Store.Delete(id)
+}
+
+// Encode Значения cookie с данными сессии на время Expires
+func (s *Config) Encode(data *Data) ([]string, error) {
+	data.Expires = time.Now().Add(s.Expires)
+	return s.Cookie.Encode(s.KeyName, data)
 }
 
 // Check Проверяем куки и извлекаем по ключу id по которому в бд/файле/памяти находим запись
@@ -81,6 +149,10 @@ func (s *Config) Check(value string) (*security.Identity, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Сессия без пользователя (например, только с сообщениями) не аутентифицирует
+	if user == "" {
+		return nil, ErrAnonymous
+	}
 	identity := &security.Identity{
 		Username: user,
 		AuthName: "Session",
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfig_Check(t *testing.T) {
+
+	s := &Config{}
+	s.Default()
+
+	if err := s.Save("anonymous", &Data{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Check("anonymous"); !errors.Is(err, ErrAnonymous) {
+		t.Errorf("anonymous session: %v", err)
+	}
+
+	if err := s.Save("alice", &Data{User: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if identity, err := s.Check("alice"); err != nil || identity.Username != "alice" {
+		t.Errorf("user session: %v %v", identity, err)
+	}
+
+	// Пустой пользователь от собственного обработчика также отклоняется
+	s.SessionHandler = func(value string) (string, error) {
+		return "", nil
+	}
+	if _, err := s.Check("alice"); !errors.Is(err, ErrAnonymous) {
+		t.Errorf("custom handler: %v", err)
+	}
+}
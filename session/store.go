@@ -0,0 +1,157 @@
+package session
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound  = errors.New("session not found")
+	ErrAnonymous = errors.New("session user is not set")
+)
+
+// Store хранилище сессий. Значение сессии хранится до истечения ttl,
+// после чего Get возвращает ErrNotFound. Реализации должны быть безопасны
+// для одновременного использования
+type Store interface {
+	// Get значение сессии или ErrNotFound
+	Get(id string) ([]byte, error)
+	// Set сохранить значение сессии со сроком жизни ttl
+	Set(id string, value []byte, ttl time.Duration) error
+	// Delete удалить сессию, отсутствие сессии ошибкой не является
+	Delete(id string) error
+	// Touch продлить срок жизни сессии или вернуть ErrNotFound
+	Touch(id string, ttl time.Duration) error
+	// GC удалить просроченные сессии
+	GC() error
+}
+
+// memoryShards количество сегментов хранилища в памяти
+const memoryShards = 32
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+type memoryShard struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+// MemoryStore хранилище сессий в памяти процесса. Сессии распределены по сегментам
+// со своими блокировками, просроченные сессии удаляются в фоне
+type MemoryStore struct {
+	shards [memoryShards]*memoryShard
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// NewMemoryStore Инициализация хранилища в памяти. Если gcInterval > 0, то просроченные
+// сессии удаляются с этим интервалом до вызова Close
+func NewMemoryStore(gcInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{stop: make(chan struct{})}
+	for i := range s.shards {
+		s.shards[i] = &memoryShard{entries: map[string]memoryEntry{}}
+	}
+	if gcInterval > 0 {
+		go s.gc(gcInterval)
+	}
+	return s
+}
+
+func (s *MemoryStore) shard(id string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return s.shards[h.Sum32()%memoryShards]
+}
+
+func (s *MemoryStore) Get(id string) ([]byte, error) {
+	shard := s.shard(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	entry, ok := shard.entries[id]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, ErrNotFound
+	}
+	return append([]byte{}, entry.value...), nil
+}
+
+func (s *MemoryStore) Set(id string, value []byte, ttl time.Duration) error {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	shard.entries[id] = memoryEntry{
+		value:   append([]byte{}, value...),
+		expires: time.Now().Add(ttl),
+	}
+	shard.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	delete(shard.entries, id)
+	shard.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Touch(id string, ttl time.Duration) error {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	entry, ok := shard.entries[id]
+	if !ok || time.Now().After(entry.expires) {
+		return ErrNotFound
+	}
+	entry.expires = time.Now().Add(ttl)
+	shard.entries[id] = entry
+	return nil
+}
+
+func (s *MemoryStore) GC() error {
+	now := time.Now()
+	for _, shard := range s.shards {
+		shard.mu.Lock()
+		for id, entry := range shard.entries {
+			if now.After(entry.expires) {
+				delete(shard.entries, id)
+			}
+		}
+		shard.mu.Unlock()
+	}
+	return nil
+}
+
+// Len количество сессий, включая еще не удаленные просроченные
+func (s *MemoryStore) Len() (n int) {
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		n += len(shard.entries)
+		shard.mu.RUnlock()
+	}
+	return
+}
+
+// Close остановить фоновое удаление просроченных сессий
+func (s *MemoryStore) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
+
+func (s *MemoryStore) gc(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.GC()
+		case <-s.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package session
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStore общая проверка реализаций Store
+func testStore(t *testing.T, s Store) {
+
+	if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get missing: %v", err)
+	}
+	if err := s.Touch("missing", time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Errorf("touch missing: %v", err)
+	}
+	if err := s.Delete("missing"); err != nil {
+		t.Errorf("delete missing: %v", err)
+	}
+
+	if err := s.Set("a", []byte(`{"user":"a"}`), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := s.Get("a"); err != nil || string(b) != `{"user":"a"}` {
+		t.Errorf("get: %s %v", b, err)
+	}
+	if err := s.Touch("a", time.Minute); err != nil {
+		t.Errorf("touch: %v", err)
+	}
+	if err := s.Delete("a"); err != nil {
+		t.Errorf("delete: %v", err)
+	}
+	if _, err := s.Get("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get deleted: %v", err)
+	}
+
+	// Истечение срока жизни
+	if err := s.Set("b", []byte("b"), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := s.Get("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get expired: %v", err)
+	}
+	if err := s.GC(); err != nil {
+		t.Errorf("gc: %v", err)
+	}
+
+	// Одновременное использование
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := strconv.Itoa(i)
+			for j := 0; j < 20; j++ {
+				if err := s.Set(id, []byte(id), time.Minute); err != nil {
+					t.Error(err)
+					return
+				}
+				if b, err := s.Get(id); err != nil || string(b) != id {
+					t.Errorf("concurrent get %s: %s %v", id, b, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(10 * time.Millisecond)
+	defer s.Close()
+	testStore(t, s)
+
+	// Фоновое удаление просроченных сессий
+	_ = s.Set("c", nil, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if n := s.Len(); n != 8 {
+		t.Errorf("sessions after gc %d, want 8", n)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+// redisStandIn минимальный сервер RESP с командами GET, SET PX, DEL, PEXPIRE и AUTH
+func redisStandIn(t *testing.T, password string) string {
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	var mu sync.Mutex
+	values := map[string]string{}
+	expires := map[string]time.Time{}
+	alive := func(key string) bool {
+		if _, ok := values[key]; !ok || time.Now().After(expires[key]) {
+			delete(values, key)
+			return false
+		}
+		return true
+	}
+
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		authorized := password == ""
+		for {
+			reply, err := readReply(r)
+			if err != nil {
+				return
+			}
+			var args []string
+			for _, item := range reply.([]interface{}) {
+				args = append(args, string(item.([]byte)))
+			}
+			mu.Lock()
+			var out string
+			switch cmd := strings.ToUpper(args[0]); {
+			case cmd == "AUTH":
+				authorized = args[1] == password
+				out = "+OK\r\n"
+				if !authorized {
+					out = "-WRONGPASS invalid password\r\n"
+				}
+			case !authorized:
+				out = "-NOAUTH Authentication required\r\n"
+			case cmd == "GET":
+				out = "$-1\r\n"
+				if alive(args[1]) {
+					out = "$" + strconv.Itoa(len(values[args[1]])) + "\r\n" + values[args[1]] + "\r\n"
+				}
+			case cmd == "SET":
+				ms, _ := strconv.Atoi(args[4])
+				if ms <= 0 {
+					out = "-ERR invalid expire time in 'set' command\r\n"
+					break
+				}
+				values[args[1]] = args[2]
+				expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+				out = "+OK\r\n"
+			case cmd == "DEL":
+				out = ":0\r\n"
+				if alive(args[1]) {
+					delete(values, args[1])
+					out = ":1\r\n"
+				}
+			case cmd == "PEXPIRE":
+				out = ":0\r\n"
+				if alive(args[1]) {
+					ms, _ := strconv.Atoi(args[2])
+					if ms <= 0 {
+						delete(values, args[1])
+					}
+					expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+					out = ":1\r\n"
+				}
+			default:
+				out = "-ERR unknown command\r\n"
+			}
+			mu.Unlock()
+			if _, err = conn.Write([]byte(out)); err != nil {
+				return
+			}
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestRedisStore(t *testing.T) {
+
+	addr := redisStandIn(t, "secret")
+
+	s := NewRedisStore(addr)
+	s.Password = "secret"
+	defer s.Close()
+	testStore(t, s)
+
+	// Срок меньше миллисекунды не превращается в недопустимый PX 0
+	if err := s.Set("c", []byte("c"), 500*time.Microsecond); err != nil {
+		t.Errorf("set short ttl: %v", err)
+	}
+	if err := s.Set("c", []byte("c"), 0); err != nil {
+		t.Errorf("set zero ttl: %v", err)
+	}
+
+	wrong := &RedisStore{Addr: addr, Password: "wrong"}
+	if _, err := wrong.Get("a"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("wrong password: %v", err)
+	}
+}
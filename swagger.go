@@ -5,6 +5,8 @@ import (
 	"github.com/egovorukhin/egowebapi/security"
 	"github.com/mustan989/jsonschema"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 type Swagger struct {
@@ -25,6 +27,8 @@ type Swagger struct {
 	ExternalDocs        *ExternalDocs          `json:"externalDocs,omitempty"`
 	Definitions         jsonschema.Definitions `json:"definitions,omitempty"`
 	models              Models
+	version             string
+	dangling            LintIssues
 	//spec.Swagger
 }
 
@@ -82,56 +86,122 @@ const (
 	TypeInteger = "integer"
 	TypeObject  = "object"
 	TypeBoolean = "boolean"
+	TypeNumber  = "number"
+
+	FormatDate     = "date"
+	FormatDateTime = "date-time"
 
 	CollectionFormatMulti = "multi"
 
 	RefDefinitions = "#/definitions/"
 )
 
-// JSON Преобразование в структуру json
+// JSON Преобразование в структуру json в формате выбранной версии
 func (s *Swagger) JSON() ([]byte, error) {
+	if s.isOpenAPI3() {
+		doc, err := s.OpenAPI()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	}
 	return json.Marshal(s)
 }
 
 // setDefinition Преобразование модели в формат JSON Schema
 func (s *Swagger) setDefinition(model interface{}, name string) *Swagger {
+	schema := s.reflect(model, name)
+	for key, value := range schema.Definitions {
+		s.Definitions[key] = value
+	}
+	return s
+}
+
+// reflect Схема модели с определениями всех вложенных типов
+func (s *Swagger) reflect(model interface{}, name string) *jsonschema.Schema {
 	r := jsonschema.Reflector{}
 	if len(name) > 0 {
+		root := reflect.TypeOf(model)
+		if root.Kind() == reflect.Ptr {
+			root = root.Elem()
+		}
+		// Каждая зарегистрированная модель получает собственное имя
 		r.Namer = func(r reflect.Type) string {
 			if r.Kind() == reflect.Ptr {
 				r = r.Elem()
 			}
-			if r.Kind() == reflect.Struct && s.models.contains(r) {
+			if r == root {
 				return name
 			}
+			if key, ok := s.models.key(r); ok {
+				return key
+			}
 			return r.Name()
 		}
 	}
-	schema := r.Reflect(model)
-	for key, value := range schema.Definitions {
-		s.Definitions[key] = value
-	}
-	return s
+	return r.Reflect(model)
 }
 
-// contains Проверка на соответствие модели
-func (m Models) contains(mt reflect.Type) bool {
-	for _, value := range m {
-		vt := reflect.TypeOf(value)
+// key Имя, под которым зарегистрирована модель
+func (m Models) key(mt reflect.Type) (string, bool) {
+	if mt.Kind() != reflect.Struct {
+		return "", false
+	}
+	for _, key := range sortedModels(m) {
+		vt := reflect.TypeOf(m[key])
 		if vt.Kind() == reflect.Ptr {
 			vt = vt.Elem()
 		}
-		if vt.Kind() == reflect.Struct {
-			if vt == mt {
-				return true
-			}
+		if vt == mt {
+			return key, true
 		}
 	}
-	return false
+	return "", false
+}
+
+// sortedModels имена моделей в алфавитном порядке
+func sortedModels(m Models) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// snapshot копия документа для отката неудачного Build. Копируются коллекции,
+// которые Build дополняет
+func (s *Swagger) snapshot() Swagger {
+	c := *s
+	c.Schemes = append([]string(nil), s.Schemes...)
+	c.Tags = append([]Tag(nil), s.Tags...)
+	c.dangling = append(LintIssues(nil), s.dangling...)
+	c.Paths = Paths{}
+	for path, item := range s.Paths {
+		c.Paths[path] = PathItem{}
+		for method, operation := range item {
+			c.Paths[path][method] = operation
+		}
+	}
+	c.SecurityDefinitions = SecurityDefinitions{}
+	for key, value := range s.SecurityDefinitions {
+		c.SecurityDefinitions[key] = value
+	}
+	c.Definitions = jsonschema.Definitions{}
+	for key, value := range s.Definitions {
+		c.Definitions[key] = value
+	}
+	c.models = Models{}
+	for key, value := range s.models {
+		c.models[key] = value
+	}
+	return c
 }
 
 // setRefDefinitions Проверка модели на существование
 func (s *Swagger) setRefDefinitions(ref string) (string, bool) {
+	// Ссылка могла быть установлена при предыдущем добавлении операции
+	ref = strings.TrimPrefix(ref, RefDefinitions)
 	if model, ok := s.models[ref]; ok {
 		s.setDefinition(model, ref)
 		return RefDefinitions + ref, ok
@@ -145,9 +215,11 @@ func (s *Swagger) SetSchemes(scheme ...string) *Swagger {
 	return s
 }
 
-// setPort добавление порта к хосту
+// setPort добавление порта к хосту, если порт не указан явно
 func (s *Swagger) setPort(port string) *Swagger {
-	s.Host += port
+	if s.Host != "" && !strings.Contains(s.Host, ":") {
+		s.Host += port
+	}
 	return s
 }
 
@@ -155,31 +227,29 @@ func (s *Swagger) setPort(port string) *Swagger {
 func (s *Swagger) setPath(path, method string, operation Operation) *Swagger {
 
 	// Настраиваем ссылку на модель в ответах
-	for _, response := range operation.Responses {
+	for code, response := range operation.Responses {
 		if response.Schema == nil {
 			continue
 		}
 		// Пытаемся найти модель в определениях
-		if !s.setSchemaRef(response.Schema) {
+		if ref, ok := s.setSchemaRef(response.Schema); !ok {
+			s.addDangling(path, method, "response "+code, ref)
 			response.Schema = nil
 		}
 	}
 
 	// Настраиваем ссылку на модель в параметрах
-	for i, param := range operation.Parameters {
-		if param == nil {
-			continue
-		}
-		switch param.In {
-		case InBody:
-			if param.Schema == nil {
-				break
-			}
-			if !s.setSchemaRef(param.Schema) {
-				operation.Parameters = append(operation.Parameters[:i], operation.Parameters[i+1:]...)
+	params := make([]*Parameter, 0, len(operation.Parameters))
+	for _, param := range operation.Parameters {
+		if param != nil && param.In == InBody && param.Schema != nil {
+			if ref, ok := s.setSchemaRef(param.Schema); !ok {
+				s.addDangling(path, method, "body parameter "+param.Name, ref)
+				continue
 			}
 		}
+		params = append(params, param)
 	}
+	operation.Parameters = params
 
 	// Проверяем ключ на существование
 	if _, ok := s.Paths[path]; !ok {
@@ -191,14 +261,19 @@ func (s *Swagger) setPath(path, method string, operation Operation) *Swagger {
 	return s
 }
 
-func (s *Swagger) setSchemaRef(schema *Schema) (exists bool) {
+// setSchemaRef Устанавливаем ссылку на определение, ref - имя ненайденной модели
+func (s *Swagger) setSchemaRef(schema *Schema) (ref string, exists bool) {
+	ref = schema.Ref
 	schema.Ref, exists = s.setRefDefinitions(schema.Ref)
 	if !exists {
 		if schema.Items != nil {
+			if ref == "" {
+				ref = schema.Items.Ref
+			}
 			schema.Items.Ref, exists = s.setRefDefinitions(schema.Items.Ref)
 		}
 	}
-	return exists
+	return strings.TrimPrefix(ref, RefDefinitions), exists
 }
 
 // setSecurityDefinition Устанавливаем необходимые поля для определения авторизации
@@ -0,0 +1,388 @@
+package egowebapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mustan989/jsonschema"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError ошибка проверки параметра запроса
+type FieldError struct {
+	In      string `json:"in"`
+	Name    string `json:"name"`
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError список ошибок проверки запроса. Ошибки параметров отправляются с кодом 400,
+// ошибки тела запроса с кодом 422
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e ValidationError) Error() string {
+	var s []string
+	for _, err := range e.Errors {
+		s = append(s, fmt.Sprintf("%s %s%s: %s", err.In, err.Name, err.Pointer, err.Message))
+	}
+	return strings.Join(s, "; ")
+}
+
+// validateParameters проверка параметров пути, адресной строки и заголовков
+// по тем же описаниям, из которых формируется swagger
+func validateParameters(c *Context, params []*Parameter) (errs []FieldError) {
+
+	for _, param := range params {
+		if param == nil {
+			continue
+		}
+
+		var values []string
+		switch param.In {
+		case InQuery:
+			values = c.QueryValues()[param.Name]
+		case InHeader:
+			if value := c.Get(param.Name); value != "" {
+				values = []string{value}
+			}
+		case InPath:
+			// Параметр пути может отсутствовать, если маршрут зарегистрирован без него (SetEmptyParam)
+			if value := c.Params(param.Name); value != "" {
+				values = []string{value}
+			}
+		default:
+			continue
+		}
+
+		for _, message := range param.validate(values) {
+			errs = append(errs, FieldError{
+				In:      param.In,
+				Name:    param.Name,
+				Message: message,
+			})
+		}
+	}
+
+	return
+}
+
+// validate проверка значений параметра на обязательность, тип и допустимые значения
+func (p *Parameter) validate(values []string) (messages []string) {
+
+	if len(values) == 0 {
+		if p.Required && p.In != InPath {
+			messages = append(messages, "is required")
+		}
+		return
+	}
+
+	t, format := p.Type, p.Format
+	if t == TypeArray && p.Items != nil {
+		t, format = p.Items.Type, p.Items.Format
+	}
+
+	for _, value := range values {
+		if value == "" {
+			if p.Required && !p.AllowEmptyValue {
+				messages = append(messages, "must not be empty")
+			}
+			continue
+		}
+		if err := checkType(value, t, format); err != nil {
+			messages = append(messages, err.Error())
+			continue
+		}
+		if p.Items != nil && len(p.Items.Enum) > 0 && !inEnum(value, p.Items.Enum) {
+			messages = append(messages, fmt.Sprintf("value %q is not one of %v", value, p.Items.Enum))
+		}
+	}
+
+	return
+}
+
+// checkType проверка соответствия строкового значения типу и формату swagger
+func checkType(value, t, format string) error {
+
+	switch t {
+	case TypeInteger:
+		bits := 64
+		if format == "int8" || format == "int16" || format == "int32" {
+			bits, _ = strconv.Atoi(format[3:])
+		}
+		if _, err := strconv.ParseInt(value, 10, bits); err != nil {
+			return fmt.Errorf("value %q is not a valid %s", value, TypeInteger)
+		}
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("value %q is not a valid %s", value, TypeNumber)
+		}
+	case TypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value %q is not a valid %s", value, TypeBoolean)
+		}
+	case TypeString:
+		switch format {
+		case FormatDateTime:
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return fmt.Errorf("value %q is not a valid %s", value, FormatDateTime)
+			}
+		case FormatDate:
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return fmt.Errorf("value %q is not a valid %s", value, FormatDate)
+			}
+		}
+	}
+
+	return nil
+}
+
+// inEnum проверка вхождения значения в список допустимых
+func inEnum(value string, enum []interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// bodySchema схема JSON тела запроса, построенная из тех же определений, что и в swagger
+type bodySchema struct {
+	required    bool
+	schema      *jsonschema.Schema
+	definitions jsonschema.Definitions
+}
+
+// newBodySchema формирование схемы по параметру body маршрута
+func newBodySchema(swagger *Swagger, params []*Parameter) *bodySchema {
+
+	for _, param := range params {
+		if param == nil || param.In != InBody || param.Schema == nil {
+			continue
+		}
+
+		ref, isArray := param.Schema.Ref, false
+		if ref == "" && param.Schema.Items != nil {
+			ref, isArray = param.Schema.Items.Ref, true
+		}
+		name := strings.TrimPrefix(ref, RefDefinitions)
+		model, ok := swagger.models[name]
+		if !ok {
+			return nil
+		}
+
+		root := swagger.reflect(model, name)
+		schema := &jsonschema.Schema{Ref: root.Ref}
+		if isArray {
+			schema = &jsonschema.Schema{Type: TypeArray, Items: schema}
+		}
+		return &bodySchema{
+			required:    param.Required,
+			schema:      schema,
+			definitions: root.Definitions,
+		}
+	}
+
+	return nil
+}
+
+// validate проверка тела запроса, ошибки содержат путь к значению в формате JSON Pointer
+func (b *bodySchema) validate(body []byte) (errs []FieldError) {
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if b.required {
+			errs = append(errs, b.error("", "request body is required"))
+		}
+		return
+	}
+
+	var value interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return append(errs, b.error("", err.Error()))
+	}
+
+	b.check(b.schema, value, "", &errs)
+	return
+}
+
+func (b *bodySchema) error(pointer, message string) FieldError {
+	return FieldError{
+		In:      InBody,
+		Name:    InBody,
+		Pointer: pointer,
+		Message: message,
+	}
+}
+
+// check рекурсивная проверка значения по схеме
+func (b *bodySchema) check(schema *jsonschema.Schema, value interface{}, pointer string, errs *[]FieldError) {
+
+	if schema == nil || schema == jsonschema.TrueSchema {
+		return
+	}
+	if schema == jsonschema.FalseSchema {
+		*errs = append(*errs, b.error(pointer, "value is not allowed"))
+		return
+	}
+
+	// Ссылка на определение
+	if schema.Ref != "" {
+		if def, ok := b.definitions[strings.TrimPrefix(schema.Ref, RefDefinitions)]; ok {
+			b.check(def, value, pointer, errs)
+		}
+		return
+	}
+
+	for _, s := range schema.AllOf {
+		b.check(s, value, pointer, errs)
+	}
+	if len(schema.AnyOf) > 0 && b.matches(schema.AnyOf, value) == 0 {
+		*errs = append(*errs, b.error(pointer, "value does not match any of the schemas"))
+	}
+	if len(schema.OneOf) > 0 && b.matches(schema.OneOf, value) != 1 {
+		*errs = append(*errs, b.error(pointer, "value must match exactly one schema"))
+	}
+
+	if schema.Type != "" && !isJSONType(value, schema.Type) {
+		*errs = append(*errs, b.error(pointer, fmt.Sprintf("expected %s, got %s", schema.Type, jsonType(value))))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(fmt.Sprint(value), schema.Enum) {
+		*errs = append(*errs, b.error(pointer, fmt.Sprintf("value is not one of %v", schema.Enum)))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, b.error(pointer+"/"+escapePointer(name), "property is required"))
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			p := pointer + "/" + escapePointer(name)
+			if schema.Properties != nil {
+				if s, ok := schema.Properties.Get(name); ok {
+					// null допустим для необязательных свойств
+					if v[name] == nil && !contains(schema.Required, name) {
+						continue
+					}
+					if s, ok := s.(*jsonschema.Schema); ok {
+						b.check(s, v[name], p, errs)
+					}
+					continue
+				}
+			}
+			if schema.AdditionalProperties == jsonschema.FalseSchema {
+				*errs = append(*errs, b.error(p, "additional property is not allowed"))
+				continue
+			}
+			if schema.AdditionalProperties != nil {
+				b.check(schema.AdditionalProperties, v[name], p, errs)
+			}
+		}
+	case []interface{}:
+		if schema.MinItems > 0 && len(v) < schema.MinItems {
+			*errs = append(*errs, b.error(pointer, fmt.Sprintf("must have at least %d items", schema.MinItems)))
+		}
+		if schema.MaxItems > 0 && len(v) > schema.MaxItems {
+			*errs = append(*errs, b.error(pointer, fmt.Sprintf("must have at most %d items", schema.MaxItems)))
+		}
+		for i, item := range v {
+			b.check(schema.Items, item, pointer+"/"+strconv.Itoa(i), errs)
+		}
+	case string:
+		if schema.MinLength > 0 && len([]rune(v)) < schema.MinLength {
+			*errs = append(*errs, b.error(pointer, fmt.Sprintf("must be at least %d characters", schema.MinLength)))
+		}
+		if schema.MaxLength > 0 && len([]rune(v)) > schema.MaxLength {
+			*errs = append(*errs, b.error(pointer, fmt.Sprintf("must be at most %d characters", schema.MaxLength)))
+		}
+		if schema.Pattern != "" {
+			if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(v) {
+				*errs = append(*errs, b.error(pointer, fmt.Sprintf("must match pattern %s", schema.Pattern)))
+			}
+		}
+		if err := checkType(v, TypeString, schema.Format); err != nil {
+			*errs = append(*errs, b.error(pointer, err.Error()))
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if schema.Minimum != 0 && (f < float64(schema.Minimum) || schema.ExclusiveMinimum && f == float64(schema.Minimum)) {
+			*errs = append(*errs, b.error(pointer, fmt.Sprintf("must be greater than %d", schema.Minimum)))
+		}
+		if schema.Maximum != 0 && (f > float64(schema.Maximum) || schema.ExclusiveMaximum && f == float64(schema.Maximum)) {
+			*errs = append(*errs, b.error(pointer, fmt.Sprintf("must be less than %d", schema.Maximum)))
+		}
+	}
+}
+
+// matches количество схем, которым соответствует значение
+func (b *bodySchema) matches(schemas []*jsonschema.Schema, value interface{}) (n int) {
+	for _, s := range schemas {
+		var errs []FieldError
+		b.check(s, value, "", &errs)
+		if errs == nil {
+			n++
+		}
+	}
+	return
+}
+
+// jsonType тип значения в терминах JSON Schema
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return TypeInteger
+		}
+		return TypeNumber
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// isJSONType проверка соответствия значения типу, целое число также является number
+func isJSONType(value interface{}, t string) bool {
+	vt := jsonType(value)
+	return vt == t || t == TypeNumber && vt == TypeInteger
+}
+
+// escapePointer экранирование имени свойства для JSON Pointer (RFC 6901)
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys(m map[string]interface{}) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package egowebapi
+
+import (
+	"encoding/json"
+	"github.com/egovorukhin/egowebapi/nethttp"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Search struct {
+	Id    int       `ewa:"path:/{id}"`
+	Page  int       `ewa:"query:required"`
+	Sort  string    `ewa:"query:array=asc&desc"`
+	Since time.Time `ewa:"query:name=since"`
+	Debug bool      `ewa:"header:name=X-Debug"`
+}
+
+type Searches struct{}
+
+func (Searches) Get(route *Route) {
+	route.SetParameters(ModelToParameters(Search{})...).Validation()
+	route.Handler = func(c *Context) error {
+		return c.SendStatus(200)
+	}
+}
+
+func TestRoute_Validation(t *testing.T) {
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(NewContext(c))
+			})
+		},
+	})
+	s.Register(new(Searches)).SetPath("/search")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("GET", "/search/1?page=2&sort=asc&since=2022-01-02T15:04:05Z", nil))
+	if w.Code != 200 {
+		t.Errorf("status %d, body %s, want 200", w.Code, w.Body.String())
+	}
+
+	r := httptest.NewRequest("GET", "/search/x?sort=up&since=yesterday", nil)
+	r.Header.Set("X-Debug", "maybe")
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, r)
+	if w.Code != 400 {
+		t.Fatalf("status %d, want 400", w.Code)
+	}
+	var ve ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &ve); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"path id": true, "query page": true, "query sort": true, "query since": true, "header X-Debug": true}
+	for _, e := range ve.Errors {
+		delete(want, e.In+" "+e.Name)
+	}
+	if len(want) != 0 {
+		t.Errorf("errors %+v, missing %v", ve.Errors, want)
+	}
+
+	if _, ok := s.Swagger.Paths["search/{id}"]["get"].Responses["400"]; !ok {
+		t.Error("response 400 is not described")
+	}
+}
+
+type Address struct {
+	City string `json:"city"`
+}
+
+type Person struct {
+	Name      string    `json:"name"`
+	Age       int       `json:"age,omitempty"`
+	Addresses []Address `json:"addresses,omitempty"`
+}
+
+type People struct{}
+
+func (People) Post(route *Route) {
+	route.SetParameters(NewBodyParam(true, "Person", false))
+	route.Handler = func(c *Context) error {
+		var person Person
+		if err := c.BodyParser(&person); err != nil {
+			return err
+		}
+		return c.SendString(200, person.Name)
+	}
+}
+
+func TestRoute_ValidationBody(t *testing.T) {
+
+	web := &nethttp.Server{}
+	s := New(web, Config{
+		Validation: true,
+		ContextHandler: func(handler Handler) interface{} {
+			return nethttp.HandlerFunc(func(c *nethttp.Context) error {
+				return handler(NewContext(c))
+			})
+		},
+	})
+	s.Swagger.SetModel("Person", Person{})
+	s.Register(new(People)).SetPath("/people")
+	if err := s.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Ivan","addresses":[{"city":"Moscow"}]}`)))
+	if w.Code != 200 || w.Body.String() != "Ivan" {
+		t.Errorf("status %d, body %s, want 200 Ivan", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("POST", "/people", strings.NewReader(`{"age":"old","addresses":[{"city":1}],"extra":true}`)))
+	if w.Code != 422 {
+		t.Fatalf("status %d, want 422", w.Code)
+	}
+	var ve ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &ve); err != nil {
+		t.Fatal(err)
+	}
+	var pointers []string
+	for _, e := range ve.Errors {
+		pointers = append(pointers, e.Pointer)
+	}
+	want := "/name,/addresses/0/city,/age,/extra"
+	if got := strings.Join(pointers, ","); got != want {
+		t.Errorf("pointers %s, want %s", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	web.ServeHTTP(w, httptest.NewRequest("POST", "/people", nil))
+	if w.Code != 422 {
+		t.Errorf("status %d, want 422: body is required", w.Code)
+	}
+}